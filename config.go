@@ -0,0 +1,319 @@
+package junos
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// hostnameRegex matches hostnames made up of letters, digits and hyphens (RFC 1123), optionally separated
+// into labels with dots.
+var hostnameRegex = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(\.[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$`)
+
+// SystemUser contains information about each user account configured under "system login." Password hashes
+// and SSH keys are not returned; HasPassword and HasSSHKey only report whether they are set.
+type SystemUser struct {
+	Username    string
+	UID         int
+	Class       string
+	FullName    string
+	HasPassword bool
+	HasSSHKey   bool
+}
+
+type loginConfig struct {
+	Users []struct {
+		Name     string `xml:"name"`
+		UID      int    `xml:"uid"`
+		Class    string `xml:"class"`
+		FullName string `xml:"full-name"`
+		Auth     struct {
+			Password string `xml:"encrypted-password"`
+			Keys     []struct {
+				XMLName xml.Name
+			} `xml:",any"`
+		} `xml:"authentication"`
+	} `xml:"system>login>user"`
+}
+
+// SystemUsers returns every user account configured on the device, as opposed to the users that are currently
+// logged in. The accounts are read from the candidate configuration.
+func (j *Junos) SystemUsers() ([]SystemUser, error) {
+	var login loginConfig
+
+	config, err := j.GetConfigSection("system>login", "xml")
+	if err != nil {
+		return nil, err
+	}
+
+	if config == "" {
+		return nil, nil
+	}
+
+	formatted := strings.Replace(config, "\n", "", -1)
+	if err := xml.Unmarshal([]byte(formatted), &login); err != nil {
+		return nil, &ParseError{Raw: formatted, Err: fmt.Errorf("failed to parse login configuration - %w", err)}
+	}
+
+	users := make([]SystemUser, 0, len(login.Users))
+	for _, u := range login.Users {
+		user := SystemUser{
+			Username:    u.Name,
+			UID:         u.UID,
+			Class:       u.Class,
+			FullName:    u.FullName,
+			HasPassword: u.Auth.Password != "",
+		}
+
+		for _, k := range u.Auth.Keys {
+			if strings.HasPrefix(k.XMLName.Local, "ssh-") {
+				user.HasSSHKey = true
+			}
+		}
+
+		users = append(users, user)
+	}
+
+	return users, nil
+}
+
+// SetHostname loads "set system host-name" with the given name into the candidate configuration. The configuration
+// is not committed. An error is returned if the name isn't a valid hostname, which may only contain letters, digits,
+// hyphens and dots, and can't start or end with a hyphen.
+func (j *Junos) SetHostname(name string) error {
+	if name == "" || len(name) > 255 || !hostnameRegex.MatchString(name) {
+		return fmt.Errorf("%q is not a valid hostname", name)
+	}
+
+	return j.LoadSet(fmt.Sprintf("set system host-name %s", name))
+}
+
+// StaticRoute contains a static route configured under "routing-options static." NextHops are the plain
+// next-hops for the route, and QualifiedNextHops are the ones configured with their own preference or metric.
+type StaticRoute struct {
+	Destination       string
+	NextHops          []string
+	QualifiedNextHops []QualifiedNextHop
+	Discard           bool
+	Reject            bool
+}
+
+// QualifiedNextHop contains a qualified next-hop for a static route.
+type QualifiedNextHop struct {
+	NextHop    string `xml:"name"`
+	Preference int    `xml:"preference"`
+	Metric     int    `xml:"metric"`
+}
+
+type staticConfig struct {
+	Routes []struct {
+		Name              string             `xml:"name"`
+		NextHops          []string           `xml:"next-hop"`
+		QualifiedNextHops []QualifiedNextHop `xml:"qualified-next-hop"`
+		Discard           *struct{}          `xml:"discard"`
+		Reject            *struct{}          `xml:"reject"`
+	} `xml:"routing-options>static>route"`
+}
+
+// StaticRoutes returns the static routes in the main routing table (inet.0), as they are configured in the candidate
+// configuration, rather than what's installed in the forwarding table. If there are no static routes, nil is returned.
+func (j *Junos) StaticRoutes() ([]StaticRoute, error) {
+	var static staticConfig
+
+	config, err := j.GetConfigSection("routing-options>static", "xml")
+	if err != nil {
+		return nil, err
+	}
+
+	if config == "" {
+		return nil, nil
+	}
+
+	formatted := strings.Replace(config, "\n", "", -1)
+	if err := xml.Unmarshal([]byte(formatted), &static); err != nil {
+		return nil, &ParseError{Raw: formatted, Err: fmt.Errorf("failed to parse static route configuration - %w", err)}
+	}
+
+	if len(static.Routes) == 0 {
+		return nil, nil
+	}
+
+	routes := make([]StaticRoute, 0, len(static.Routes))
+	for _, r := range static.Routes {
+		routes = append(routes, StaticRoute{
+			Destination:       r.Name,
+			NextHops:          r.NextHops,
+			QualifiedNextHops: r.QualifiedNextHops,
+			Discard:           r.Discard != nil,
+			Reject:            r.Reject != nil,
+		})
+	}
+
+	return routes, nil
+}
+
+// SNMPSettings contains the SNMP configuration of the device. Only the names of the communities are returned.
+// Clients are the addresses (prefixes) allowed to poll the device, from every community and client list, excluding
+// any that are restricted. TrapTargets are the addresses traps are sent to, from every trap group.
+type SNMPSettings struct {
+	Location    string
+	Contact     string
+	Communities []string
+	Clients     []string
+	TrapTargets []string
+}
+
+type snmpConfig struct {
+	Location    string `xml:"snmp>location"`
+	Contact     string `xml:"snmp>contact"`
+	Communities []struct {
+		Name    string       `xml:"name"`
+		Clients []snmpClient `xml:"clients"`
+	} `xml:"snmp>community"`
+	ClientLists []struct {
+		Clients []snmpClient `xml:"client-address-list"`
+	} `xml:"snmp>client-list"`
+	TrapGroups []struct {
+		Targets []string `xml:"targets>name"`
+	} `xml:"snmp>trap-group"`
+}
+
+type snmpClient struct {
+	Address  string    `xml:"name"`
+	Restrict *struct{} `xml:"restrict"`
+}
+
+// SNMPConfig returns the SNMP communities, clients and trap targets configured on the device, as they are in the
+// candidate configuration. If SNMP isn't configured, an empty SNMPSettings is returned.
+func (j *Junos) SNMPConfig() (*SNMPSettings, error) {
+	var snmp snmpConfig
+
+	config, err := j.GetConfigSection("snmp", "xml")
+	if err != nil {
+		return nil, err
+	}
+
+	if config == "" {
+		return &SNMPSettings{}, nil
+	}
+
+	formatted := strings.Replace(config, "\n", "", -1)
+	if err := xml.Unmarshal([]byte(formatted), &snmp); err != nil {
+		return nil, &ParseError{Raw: formatted, Err: fmt.Errorf("failed to parse SNMP configuration - %w", err)}
+	}
+
+	settings := &SNMPSettings{
+		Location: snmp.Location,
+		Contact:  snmp.Contact,
+	}
+
+	seen := make(map[string]bool)
+	addClients := func(clients []snmpClient) {
+		for _, c := range clients {
+			if c.Restrict == nil && !seen[c.Address] {
+				seen[c.Address] = true
+				settings.Clients = append(settings.Clients, c.Address)
+			}
+		}
+	}
+
+	for _, c := range snmp.Communities {
+		settings.Communities = append(settings.Communities, c.Name)
+		addClients(c.Clients)
+	}
+
+	for _, l := range snmp.ClientLists {
+		addClients(l.Clients)
+	}
+
+	targets := make(map[string]bool)
+	for _, g := range snmp.TrapGroups {
+		for _, t := range g.Targets {
+			if !targets[t] {
+				targets[t] = true
+				settings.TrapTargets = append(settings.TrapTargets, t)
+			}
+		}
+	}
+
+	return settings, nil
+}
+
+// BuildSetCommands turns the given hierarchy paths and their values into "set" commands, one per line, which can
+// be given to LoadSet. The commands are sorted by path, so the output is always the same for the same statements.
+// Values containing spaces or characters that are special to the CLI are quoted, and an empty value sets the path
+// on its own. For example:
+//
+//	BuildSetCommands(map[string]string{
+//	    "interfaces ge-0/0/0 description": "uplink to core",
+//	    "system services ssh":             "",
+//	})
+//
+// returns:
+//
+//	set interfaces ge-0/0/0 description "uplink to core"
+//	set system services ssh
+//
+// An error is returned if a path is empty, or if a path or value contains a newline or any other control character,
+// since it would otherwise end the command and start a new one.
+func BuildSetCommands(statements map[string]string) (string, error) {
+	paths := make([]string, 0, len(statements))
+	for path := range statements {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+
+	commands := make([]string, 0, len(paths))
+	for _, path := range paths {
+		value := statements[path]
+
+		if err := checkSetStatement(path); err != nil {
+			return "", fmt.Errorf("invalid path %q - %w", path, err)
+		}
+
+		if err := checkSetStatement(value); err != nil {
+			return "", fmt.Errorf("invalid value %q for %s - %w", value, path, err)
+		}
+
+		command := "set " + strings.TrimSpace(path)
+		if command == "set " {
+			return "", errors.New("the path of a set command can't be empty")
+		}
+
+		if value != "" {
+			command += " " + quoteSetValue(value)
+		}
+
+		commands = append(commands, command)
+	}
+
+	return strings.Join(commands, "\n"), nil
+}
+
+// checkSetStatement returns an error if s contains a control character, such as a newline or carriage return.
+// Tabs are allowed, since the CLI treats them the same as spaces.
+func checkSetStatement(s string) error {
+	for _, r := range s {
+		if unicode.IsControl(r) && r != '\t' {
+			return fmt.Errorf("control character %U is not allowed", r)
+		}
+	}
+
+	return nil
+}
+
+// quoteSetValue wraps value in double quotes if the CLI would otherwise split it up, or treat part of it as
+// something other than the value. Quotes and backslashes inside it are escaped.
+func quoteSetValue(value string) string {
+	if !strings.ContainsAny(value, " \t\"'\\;{}[]#") {
+		return value
+	}
+
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	return `"` + r.Replace(value) + `"`
+}
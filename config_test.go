@@ -0,0 +1,50 @@
+package junos
+
+import "testing"
+
+func TestBuildSetCommands(t *testing.T) {
+	tests := []struct {
+		name       string
+		statements map[string]string
+		want       string
+		wantErr    bool
+	}{
+		{
+			name: "sorted and quoted",
+			statements: map[string]string{
+				"system services ssh":             "",
+				"interfaces ge-0/0/0 description": `uplink to "core"`,
+				"system host-name":                "sw1",
+			},
+			want: "set interfaces ge-0/0/0 description \"uplink to \\\"core\\\"\"\nset system host-name sw1\nset system services ssh",
+		},
+		{
+			name:       "newline in path",
+			statements: map[string]string{"snmp community x\nset system root-authentication plain-text-password": ""},
+			wantErr:    true,
+		},
+		{
+			name:       "carriage return in value",
+			statements: map[string]string{"system host-name": "sw1\rset system services telnet"},
+			wantErr:    true,
+		},
+		{
+			name:       "empty path",
+			statements: map[string]string{" ": "sw1"},
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BuildSetCommands(tt.statements)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BuildSetCommands() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("BuildSetCommands() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
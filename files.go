@@ -0,0 +1,282 @@
+package junos
+
+import (
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// FileInfo contains information about a file or directory on the device.
+type FileInfo struct {
+	Name      string
+	Size      int64
+	ModTime   time.Time
+	Directory bool
+}
+
+type fileList struct {
+	Output string `xml:"output"`
+	Files  []struct {
+		Name      string    `xml:"file-name"`
+		Size      int64     `xml:"file-size"`
+		Date      string    `xml:"file-date"`
+		Directory *struct{} `xml:"file-directory"`
+	} `xml:"directory>file-information"`
+}
+
+// scpPort is the port we copy files over when the session is using the default NETCONF port.
+const scpPort = "22"
+
+// dialSCP opens a new SSH connection to the device for copying files, using the same host and credentials
+// as the session. SCP is served by the SSH service rather than NETCONF, so if the session was opened on
+// the default NETCONF port (830), port 22 is used instead.
+func (j *Junos) dialSCP() (*ssh.Client, error) {
+	if j.host == "" || j.clientConfig == nil {
+		return nil, errors.New("file transfers require a session created with NewSession or NewSessionWithConfig")
+	}
+
+	host, port, err := net.SplitHostPort(hostWithPort(j.host, scpPort))
+	if err != nil {
+		return nil, fmt.Errorf("invalid host %s - %w", j.host, err)
+	}
+
+	if port == defaultPort {
+		port = scpPort
+	}
+
+	client, err := ssh.Dial("tcp", net.JoinHostPort(host, port), j.clientConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to %s - %w", host, err)
+	}
+
+	return client, nil
+}
+
+// shellQuote quotes s so that it is passed as a single argument to the device's shell.
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+// scpConn is an SCP process running on the device.
+type scpConn struct {
+	client  *ssh.Client
+	session *ssh.Session
+	stdin   io.WriteCloser
+	stdout  *bufio.Reader
+}
+
+// startSCP runs scp on the device with the given arguments, i.e. "-t /var/tmp" to send a file to it.
+func (j *Junos) startSCP(args string) (*scpConn, error) {
+	client, err := j.dialSCP()
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := client.NewSession()
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	scp := &scpConn{client: client, session: session}
+
+	if scp.stdin, err = session.StdinPipe(); err != nil {
+		scp.close()
+		return nil, err
+	}
+
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		scp.close()
+		return nil, err
+	}
+	scp.stdout = bufio.NewReader(stdout)
+
+	if err := session.Start("scp " + args); err != nil {
+		scp.close()
+		return nil, fmt.Errorf("failed to start scp on the device - %w", err)
+	}
+
+	return scp, nil
+}
+
+func (c *scpConn) close() {
+	c.session.Close()
+	c.client.Close()
+}
+
+// scpAck reads the response to an SCP message, returning the error sent by the device if there is one.
+func scpAck(r *bufio.Reader) error {
+	code, err := r.ReadByte()
+	if err != nil {
+		return fmt.Errorf("failed to read scp response - %w", err)
+	}
+
+	if code == 0 {
+		return nil
+	}
+
+	msg, _ := r.ReadString('\n')
+	return fmt.Errorf("scp error - %s", strings.TrimSpace(msg))
+}
+
+// UploadFile copies the file at localPath on your local machine to remotePath on the device using SCP, e.g. to
+// stage configuration files or op scripts in /var/tmp. If remotePath is a directory, the file keeps its name.
+// An error is returned if the file can't be written on the device, such as when the remote path isn't writable.
+func (j *Junos) UploadFile(localPath, remotePath string) error {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	scp, err := j.startSCP("-t " + shellQuote(remotePath))
+	if err != nil {
+		return err
+	}
+	defer scp.close()
+
+	stdin, r := scp.stdin, scp.stdout
+
+	if err := scpAck(r); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdin, "C%04o %d %s\n", info.Mode().Perm(), info.Size(), info.Name())
+	if err := scpAck(r); err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(stdin, f); err != nil {
+		return fmt.Errorf("failed to upload %s - %w", localPath, err)
+	}
+
+	fmt.Fprint(stdin, "\x00")
+	if err := scpAck(r); err != nil {
+		return err
+	}
+
+	stdin.Close()
+
+	return scp.session.Wait()
+}
+
+// DownloadFile copies the file at remotePath on the device to localPath on your local machine using SCP, e.g. to
+// collect core dumps or configuration archives. An error is returned if the file doesn't exist on the device, or
+// can't be written locally. Since the file may contain secrets, it is created with 0600 permissions.
+func (j *Junos) DownloadFile(remotePath, localPath string) error {
+	scp, err := j.startSCP("-f " + shellQuote(remotePath))
+	if err != nil {
+		return err
+	}
+	defer scp.close()
+
+	stdin, r := scp.stdin, scp.stdout
+
+	// The device waits for us to say we're ready before sending the file.
+	fmt.Fprint(stdin, "\x00")
+
+	code, err := r.ReadByte()
+	if err != nil {
+		return fmt.Errorf("failed to read scp response - %w", err)
+	}
+
+	header, err := r.ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("failed to read scp response - %w", err)
+	}
+
+	if code != 'C' {
+		return fmt.Errorf("scp error - %s", strings.TrimSpace(header))
+	}
+
+	// The header is "<mode> <size> <name>".
+	fields := strings.SplitN(strings.TrimSpace(header), " ", 3)
+	if len(fields) != 3 {
+		return fmt.Errorf("unexpected scp header - C%s", strings.TrimSpace(header))
+	}
+
+	size, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return fmt.Errorf("unexpected scp header - C%s", strings.TrimSpace(header))
+	}
+
+	f, err := os.OpenFile(localPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	fmt.Fprint(stdin, "\x00")
+
+	if _, err := io.CopyN(f, r, size); err != nil {
+		return fmt.Errorf("failed to download %s - %w", remotePath, err)
+	}
+
+	if err := scpAck(r); err != nil {
+		return err
+	}
+
+	fmt.Fprint(stdin, "\x00")
+	stdin.Close()
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to save %s - %w", localPath, err)
+	}
+
+	return scp.session.Wait()
+}
+
+// ListFiles returns the files and directories found at the given path on the device, the same as
+// "file list detail." An error is returned if the path doesn't exist.
+func (j *Junos) ListFiles(path string) ([]FileInfo, error) {
+	var list fileList
+	reply, err := j.exec(fmt.Sprintf(rpcFileList, escapeXML(path)))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := joinRPCErrors(reply); err != nil {
+		return nil, err
+	}
+
+	formatted := strings.Replace(reply.Data, "\n", "", -1)
+	if err := xml.Unmarshal([]byte(formatted), &list); err != nil {
+		return nil, &ParseError{Raw: formatted, Err: fmt.Errorf("failed to parse file list - %w", err)}
+	}
+
+	if list.Output != "" {
+		return nil, errors.New(strings.TrimSpace(list.Output))
+	}
+
+	files := make([]FileInfo, 0, len(list.Files))
+	for _, f := range list.Files {
+		info := FileInfo{
+			Name:      strings.TrimSpace(f.Name),
+			Size:      f.Size,
+			Directory: f.Directory != nil,
+		}
+
+		if secs, err := strconv.ParseInt(strings.TrimSpace(f.Date), 10, 64); err == nil {
+			info.ModTime = time.Unix(secs, 0)
+		}
+
+		files = append(files, info)
+	}
+
+	return files, nil
+}
@@ -1,6 +1,6 @@
 module github.com/scottdware/go-junos
 
-go 1.12
+go 1.13
 
 require (
 	github.com/Juniper/go-netconf v0.1.1
@@ -3,30 +3,51 @@
 package junos
 
 import (
+	"context"
+	"crypto/ed25519"
+	"crypto/tls"
+	"encoding/json"
 	"encoding/xml"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"log"
 	"net"
+	"os"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
 	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
 
 	"github.com/Juniper/go-netconf/netconf"
 )
 
+// defaultPort is the port we connect to when one isn't given with the host.
+const defaultPort = "830"
+
+// defaultTLSPort is the port we connect to for NETCONF over TLS when one isn't given with the host.
+const defaultTLSPort = "6513"
+
+// defaultShellPort is the port we connect to in shell mode (see AuthMethod) when one isn't given with the host.
+const defaultShellPort = "22"
+
 // All of our RPC calls we use.
 var (
 	rpcCommand             = "<command format=\"text\">%s</command>"
 	rpcCommandXML          = "<command format=\"xml\">%s</command>"
+	rpcCommandJSON         = "<command format=\"json\">%s</command>"
 	rpcCommit              = "<commit-configuration/>"
 	rpcCommitAt            = "<commit-configuration><at-time>%s</at-time></commit-configuration>"
 	rpcCommitAtLog         = "<commit-configuration><at-time>%s</at-time><log>%s</log></commit-configuration>"
 	rpcCommitCheck         = "<commit-configuration><check/></commit-configuration>"
 	rpcCommitConfirm       = "<commit-configuration><confirmed/><confirm-timeout>%d</confirm-timeout></commit-configuration>"
 	rpcCommitFull          = "<commit-configuration><full/></commit-configuration>"
+	rpcCommitSync          = "<commit-configuration><synchronize/></commit-configuration>"
+	rpcCommitLog           = "<commit-configuration><log>%s</log></commit-configuration>"
 	rpcFactsRE             = "<get-route-engine-information/>"
 	rpcFactsChassis        = "<get-chassis-inventory/>"
 	rpcConfigFileSet       = "<load-configuration action=\"set\" format=\"text\"><configuration-set>%s</configuration-set></load-configuration>"
@@ -38,24 +59,47 @@ var (
 	rpcConfigStringSet     = "<load-configuration action=\"set\" format=\"text\"><configuration-set>%s</configuration-set></load-configuration>"
 	rpcConfigStringText    = "<load-configuration action=\"replace\" format=\"text\"><configuration-text>%s</configuration-text></load-configuration>"
 	rpcConfigStringXML     = "<load-configuration format=\"xml\"><configuration>%s</configuration></load-configuration>"
+	rpcLoadConfigText      = "<load-configuration action=\"%s\" format=\"text\"><configuration-text>%s</configuration-text></load-configuration>"
+	rpcLoadConfigXML       = "<load-configuration action=\"%s\" format=\"xml\"><configuration>%s</configuration></load-configuration>"
+	rpcLoadConfigJSON      = "<load-configuration action=\"%s\" format=\"json\"><configuration-json>%s</configuration-json></load-configuration>"
 	rpcGetRescue           = "<get-rescue-information><format>text</format></get-rescue-information>"
 	rpcGetRollback         = "<get-rollback-information><rollback>%d</rollback><format>text</format></get-rollback-information>"
 	rpcGetRollbackCompare  = "<get-rollback-information><rollback>0</rollback><compare>%d</compare><format>text</format></get-rollback-information>"
 	rpcGetCandidateCompare = "<get-configuration compare=\"rollback\" rollback=\"%d\" format=\"text\"/>"
 	rpcHardware            = "<get-chassis-inventory/>"
 	rpcLock                = "<lock-configuration/>"
+	rpcOpenPrivate         = "<open-configuration><private/></open-configuration>"
+	rpcClosePrivate        = "<close-configuration/>"
 	rpcRescueConfig        = "<load-configuration rescue=\"rescue\"/>"
 	rpcRescueDelete        = "<request-delete-rescue-configuration/>"
 	rpcRescueSave          = "<request-save-rescue-configuration/>"
 	rpcRollbackConfig      = "<load-configuration rollback=\"%d\"/>"
+	rpcGetRollbackConfig   = "<get-configuration rollback=\"%d\" format=\"%s\"/>"
+	rpcGetCommittedConfig  = "<get-configuration database=\"committed\" format=\"%s\"/>"
 	rpcRoute               = "<get-route-engine-information/>"
 	rpcSoftware            = "<get-software-information/>"
 	rpcUnlock              = "<unlock-configuration/>"
+	rpcDiscardChanges      = "<discard-changes/>"
+	rpcGetFilter           = "<get><filter type=\"subtree\">%s</filter></get>"
+	rpcClearIntStats       = "<clear-interfaces-statistics><interface-name>%s</interface-name></clear-interfaces-statistics>"
+	rpcClearIntStatsAll    = "<clear-interfaces-statistics-all/>"
+	rpcClearBGPNeighbor    = "<clear-bgp-neighbor>%s</clear-bgp-neighbor>"
 	rpcVersion             = "<get-software-information/>"
 	rpcReboot              = "<request-reboot/>"
+	rpcRebootIn            = "<request-reboot><in>%d</in></request-reboot>"
+	rpcHalt                = "<request-halt/>"
+	rpcZeroize             = "<request-system-zeroize/>"
+	rpcSubscribe           = "<create-subscription xmlns=\"urn:ietf:params:xml:ns:netconf:notification:1.0\">%s</create-subscription>"
+	rpcSupportInfo         = "<get-support-information/>"
+	rpcOpScript            = "<op-script><script>%s</script>%s</op-script>"
+	rpcPackageAdd          = "<request-package-add><package-name>%s</package-name>%s</request-package-add>"
 	rpcCommitHistory       = "<get-commit-information/>"
 	rpcFileList            = "<file-list><detail/><path>%s</path></file-list>"
 	rpcInterfaces          = "<get-interface-information/>"
+	rpcInterfacesTerse     = "<get-interface-information><terse/></get-interface-information>"
+	rpcInterfaceExtensive  = "<get-interface-information><extensive/><interface-name>%s</interface-name></get-interface-information>"
+	rpcUptime              = "<get-system-uptime-information/>"
+	rpcFlowSessionSummary  = "<get-flow-session-information><summary/></get-flow-session-information>"
 )
 
 // Junos contains our session state.
@@ -65,6 +109,15 @@ type Junos struct {
 	RoutingEngines int
 	Platform       []RoutingEngine
 	CommitTimeout  time.Duration
+	host           string
+	user           string
+	clientConfig   *ssh.ClientConfig
+	logger         *log.Logger
+	dryRun         bool
+	allowEmpty     bool
+	locked         bool
+	timeout        time.Duration
+	shell          bool
 }
 
 // AuthMethod defines how we want to authenticate to the device. If using a
@@ -79,19 +132,56 @@ type Junos struct {
 // ~/.ssh/id_rsa
 //
 // If you do not have a passphrase tied to your private key, then you can omit this field.
+//
+// To authenticate using the keys loaded in your SSH agent, provide the username and set Agent to true.
+// The agent is reached through the socket in the SSH_AUTH_SOCK environment variable.
+//
+// Timeout is optional, and is the maximum amount of time to wait for the connection to the
+// device to be established. If it is not set, we wait as long as the operating system allows.
+//
+// KnownHosts is optional, and is the path to an OpenSSH known_hosts file, i.e. ~/.ssh/known_hosts.
+// When it is set, the device's host key is verified against it and the connection fails if the
+// key is unknown or doesn't match. Otherwise, host keys are not verified at all. Entries for the port
+// being connected to ("[host]:830") are used first; if there aren't any, the entries for the host itself,
+// which ssh writes when connecting on port 22, are used. Only the key types known for the device are
+// negotiated, so a device that prefers a key type that isn't in the file isn't mistaken for a mismatch.
+//
+// Ciphers, KeyExchanges and MACs are optional, and override the SSH algorithms offered to the device,
+// i.e. []string{"aes128-cbc", "3des-cbc"}. Older Junos devices may only support legacy algorithms
+// that are disabled by default. Be aware that these algorithms (CBC ciphers, SHA-1 based key exchanges
+// and MACs) have known weaknesses, and make the connection easier to attack; only enable them for devices
+// that can't be upgraded, and preferably only on a trusted management network. If you need to tune the
+// SSH connection further, use NewSessionWithConfig.
+//
+// Shell is optional. When it is set, NETCONF is started from the Junos CLI ("xml-mode netconf need-trailer")
+// instead of requesting the netconf SSH subsystem, for devices that don't have "system services netconf ssh"
+// configured. The default port in shell mode is 22. Shell mode has some limitations: the user must log in to
+// the CLI rather than the UNIX shell (so it won't work for root), only the older end-of-message framing is
+// supported, and anything the CLI prints before NETCONF starts, such as a login banner, may break the session.
 type AuthMethod struct {
-	Credentials []string
-	Username    string
-	PrivateKey  string
-	Passphrase  string
+	Credentials  []string
+	Username     string
+	PrivateKey   string
+	Passphrase   string
+	Agent        bool
+	Timeout      time.Duration
+	KnownHosts   string
+	Ciphers      []string
+	KeyExchanges []string
+	MACs         []string
+	Shell        bool
 }
 
-// CommitHistory holds all of the commit entries.
+// CommitHistory holds all of the commit entries. PendingConfirm is true when the latest commit was a "commit confirmed"
+// that hasn't been confirmed yet, meaning the device will roll back to the previous configuration unless another
+// commit is made in time.
 type CommitHistory struct {
-	Entries []CommitEntry `xml:"commit-history"`
+	Entries        []CommitEntry `xml:"commit-history"`
+	PendingConfirm bool          `xml:"-"`
 }
 
-// CommitEntry holds information about each prevous commit.
+// CommitEntry holds information about each prevous commit. The sequence number of an entry is the
+// rollback number of that configuration, so it can be given to Rollback or GetRollbackConfig.
 type CommitEntry struct {
 	Sequence  int    `xml:"sequence-number"`
 	User      string `xml:"user"`
@@ -101,24 +191,58 @@ type CommitEntry struct {
 	Timestamp string `xml:"date-time"`
 }
 
+// Facts contains basic information about the device. On devices with multiple routing engines,
+// or clustered SRX's, the information is taken from the first one.
+type Facts struct {
+	Hostname     string
+	Model        string
+	Version      string
+	SerialNumber string
+	Uptime       string
+}
+
 // RoutingEngine contains the hardware and software information for each route engine.
 type RoutingEngine struct {
 	Model   string
 	Version string
 }
 
+type rebootResults struct {
+	Status string `xml:"request-reboot-status"`
+}
+
+type multiRebootResults struct {
+	XMLName xml.Name `xml:"multi-routing-engine-results"`
+	RE      []struct {
+		Name   string `xml:"re-name"`
+		Status string `xml:"request-reboot-results>request-reboot-status"`
+	} `xml:"multi-routing-engine-item"`
+}
+
+type packageResults struct {
+	Result int      `xml:"package-result"`
+	Output []string `xml:"output"`
+}
+
 type commandXML struct {
 	Config string `xml:",innerxml"`
 }
 
 type commitError struct {
-	Path    string `xml:"error-path"`
-	Element string `xml:"error-info>bad-element"`
-	Message string `xml:"error-message"`
+	Severity string `xml:"error-severity"`
+	Path     string `xml:"error-path"`
+	Element  string `xml:"error-info>bad-element"`
+	Message  string `xml:"error-message"`
 }
 
 type commitResults struct {
-	XMLName xml.Name      `xml:"commit-results"`
+	XMLName  xml.Name      `xml:"commit-results"`
+	Errors   []commitError `xml:"rpc-error"`
+	REErrors []commitError `xml:"routing-engine>rpc-error"`
+}
+
+type loadResults struct {
+	XMLName xml.Name      `xml:"load-configuration-results"`
 	Errors  []commitError `xml:"rpc-error"`
 }
 
@@ -146,9 +270,82 @@ type hardwareRouteEngine struct {
 	Description string   `xml:"chassis>description"`
 }
 
-type versionRouteEngines struct {
+// SystemUptime contains the times reported by "show system uptime." Uptime is how long it has been since
+// the system booted. Times that the device doesn't report are left as the zero time.
+type SystemUptime struct {
+	CurrentTime          time.Time
+	SystemBootedTime     time.Time
+	ProtocolsStartedTime time.Time
+	LastConfiguredTime   time.Time
+	LastConfiguredBy     string
+	Uptime               time.Duration
+}
+
+type systemUptimes struct {
+	XMLName xml.Name             `xml:"multi-routing-engine-results"`
+	RE      []systemUptimeResult `xml:"multi-routing-engine-item>system-uptime-information"`
+}
+
+// flowSessionSummary holds the summary for each SPU, or for the whole device on branch SRX's.
+type flowSessionSummary struct {
+	Summaries []struct {
+		ActiveSessions int `xml:"active-sessions"`
+	} `xml:"flow-session-summary-information"`
+}
+
+type flowSessionSummaries struct {
 	XMLName xml.Name             `xml:"multi-routing-engine-results"`
-	RE      []versionRouteEngine `xml:"multi-routing-engine-item>software-information"`
+	Nodes   []flowSessionSummary `xml:"multi-routing-engine-item>flow-session-information"`
+}
+
+type systemUptimeResult struct {
+	CurrentTime      uptimeSeconds `xml:"current-time>date-time"`
+	BootedTime       uptimeSeconds `xml:"system-booted-time>date-time"`
+	BootedLength     uptimeSeconds `xml:"system-booted-time>time-length"`
+	ProtocolsStarted uptimeSeconds `xml:"protocols-started-time>date-time"`
+	LastConfigured   uptimeSeconds `xml:"last-configured-time>date-time"`
+	LastConfiguredBy string        `xml:"last-configured-time>user"`
+}
+
+// uptimeSeconds holds the junos:seconds attribute that accompanies each date and length of time
+// in the uptime information, which is far easier to parse than the text itself.
+type uptimeSeconds struct {
+	Seconds int64 `xml:"seconds,attr"`
+}
+
+func (u uptimeSeconds) time() time.Time {
+	if u.Seconds == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(u.Seconds, 0)
+}
+
+type uptimeRouteEngines struct {
+	XMLName xml.Name `xml:"multi-routing-engine-results"`
+	UpTime  []string `xml:"multi-routing-engine-item>route-engine-information>route-engine>up-time"`
+}
+
+type uptimeRouteEngine struct {
+	XMLName xml.Name `xml:"route-engine-information"`
+	UpTime  []string `xml:"route-engine>up-time"`
+}
+
+// RoutingEngineVersion contains the software information for a single routing engine. Name is
+// the routing engine or node, e.g. "re0" or "node1", and is empty on devices with only one.
+type RoutingEngineVersion struct {
+	Name     string
+	Hostname string
+	Model    string
+	Version  string
+}
+
+type versionRouteEngines struct {
+	XMLName xml.Name `xml:"multi-routing-engine-results"`
+	Items   []struct {
+		Name     string             `xml:"re-name"`
+		Software versionRouteEngine `xml:"software-information"`
+	} `xml:"multi-routing-engine-item"`
 }
 
 type versionRouteEngine struct {
@@ -158,36 +355,221 @@ type versionRouteEngine struct {
 	PackageInfo []versionPackageInfo `xml:"package-information"`
 }
 
+// version pulls the hostname, model and Junos version out of the software information.
+func (v versionRouteEngine) version() RoutingEngineVersion {
+	rex := regexp.MustCompile(`^.*\[(.*)\]`)
+	res := RoutingEngineVersion{Hostname: v.Hostname, Model: strings.ToUpper(v.Platform)}
+
+	if len(v.PackageInfo) > 0 && len(v.PackageInfo[0].SoftwareVersion) > 0 {
+		if version := rex.FindStringSubmatch(v.PackageInfo[0].SoftwareVersion[0]); len(version) == 2 {
+			res.Version = version[1]
+		}
+	}
+
+	return res
+}
+
 type versionPackageInfo struct {
 	XMLName         xml.Name `xml:"package-information"`
 	PackageName     []string `xml:"name"`
 	SoftwareVersion []string `xml:"comment"`
 }
 
+// errNotConfigured is returned by GetConfig when the requested section isn't in the configuration.
+var errNotConfigured = errors.New("the section you provided is not configured on the device")
+
+// ParseError is returned when the reply from the device can't be parsed, which usually means that it returned
+// an XML structure we don't expect, such as from a newer release of Junos. Raw contains the data that was sent by
+// the device, so that you can inspect it:
+//
+//	var perr *junos.ParseError
+//	if errors.As(err, &perr) {
+//	    fmt.Println(perr.Raw)
+//	}
+type ParseError struct {
+	Raw string
+	Err error
+}
+
+// Error returns the error that occurred while parsing the reply.
+func (e *ParseError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error, so that ParseError works with errors.Is and errors.As.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// interfaceNameRegex matches the names of physical and logical interfaces, i.e. "ge-0/0/0", "ae0.100",
+// "xe-0/0/0:1" or "irb.10," including wildcards such as "ge-0/0/*".
+var interfaceNameRegex = regexp.MustCompile(`^[a-zA-Z*][a-zA-Z0-9*-]*(/[0-9*]+)*(:[0-9*]+)?(\.[0-9*]+)?$`)
+
+// ValidateInterfaceName returns an error if name is not a well-formed interface name, such as "ge-0/0/0"
+// or "ae0.100." The methods in this package that take an interface name check it with this before
+// sending it to the device.
+func ValidateInterfaceName(name string) error {
+	if name == "" {
+		return errors.New("the interface name can't be empty")
+	}
+
+	if len(name) > 64 || !interfaceNameRegex.MatchString(name) {
+		return fmt.Errorf("%q is not a valid interface name", name)
+	}
+
+	return nil
+}
+
+// escapeXML escapes any characters in s that would otherwise break the XML of the RPC it's
+// placed in, such as quotes, ampersands and angle brackets.
+func escapeXML(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+
+	return b.String()
+}
+
+// replyText returns all of the text in the given reply data, without any of its XML elements, one line for
+// each element that contains text. It's used for replies that are only an acknowledgment, such as "<output>."
+func replyText(data string) string {
+	var lines []string
+
+	d := xml.NewDecoder(strings.NewReader("<reply>" + data + "</reply>"))
+	for {
+		t, err := d.Token()
+		if err != nil {
+			break
+		}
+
+		if c, ok := t.(xml.CharData); ok {
+			if line := strings.TrimSpace(string(c)); line != "" {
+				lines = append(lines, line)
+			}
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
 // genSSHClientConfig is a wrapper function based around the auth method defined
 // (user/password or private key) which returns the SSH client configuration used to
 // connect.
-func genSSHClientConfig(auth *AuthMethod) (*ssh.ClientConfig, error) {
+func genSSHClientConfig(host string, auth *AuthMethod) (*ssh.ClientConfig, error) {
 	var config *ssh.ClientConfig
+	var err error
 
-	if len(auth.Credentials) > 0 {
+	switch {
+	case len(auth.Credentials) > 0:
 		config = netconf.SSHConfigPassword(auth.Credentials[0], auth.Credentials[1])
+	case len(auth.PrivateKey) > 0:
+		config, err = netconf.SSHConfigPubKeyFile(auth.Username, auth.PrivateKey, auth.Passphrase)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load private key %s (check the path and passphrase) - %w", auth.PrivateKey, err)
+		}
 
-		return config, nil
-	}
+		config.HostKeyCallback = ssh.InsecureIgnoreHostKey()
+	case auth.Agent:
+		if os.Getenv("SSH_AUTH_SOCK") == "" {
+			return nil, errors.New("unable to use the SSH agent - SSH_AUTH_SOCK is not set")
+		}
 
-	if len(auth.PrivateKey) > 0 {
-		config, err := netconf.SSHConfigPubKeyFile(auth.Username, auth.PrivateKey, auth.Passphrase)
+		config, err = netconf.SSHConfigPubKeyAgent(auth.Username)
 		if err != nil {
-			return config, err
+			return nil, fmt.Errorf("unable to connect to the SSH agent - %w", err)
 		}
 
 		config.HostKeyCallback = ssh.InsecureIgnoreHostKey()
+	default:
+		return nil, errors.New("no credentials/keys available")
+	}
+
+	config.Timeout = auth.Timeout
+	config.Ciphers = auth.Ciphers
+	config.KeyExchanges = auth.KeyExchanges
+	config.MACs = auth.MACs
+
+	if len(auth.KnownHosts) > 0 {
+		callback, err := knownhosts.New(auth.KnownHosts)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load known hosts file %s - %w", auth.KnownHosts, err)
+		}
+
+		config.HostKeyCallback = func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+			err := callback(hostname, remote, key)
+
+			var keyErr *knownhosts.KeyError
+			if errors.As(err, &keyErr) && len(keyErr.Want) == 0 {
+				err = callback(withSSHPort(hostname), remote, key)
+			}
+
+			if err != nil {
+				return fmt.Errorf("host key verification failed for %s - %w", hostname, err)
+			}
+
+			return nil
+		}
+
+		port := defaultPort
+		if auth.Shell {
+			port = defaultShellPort
+		}
+
+		config.HostKeyAlgorithms = knownHostKeyAlgorithms(callback, hostWithPort(host, port))
+	}
+
+	return config, nil
+}
+
+// withSSHPort replaces the port of the given host:port with the SSH port, 22, which is how ssh stores entries
+// for the host itself in known_hosts.
+func withSSHPort(hostport string) string {
+	host, _, err := net.SplitHostPort(hostport)
+	if err != nil {
+		host = hostport
+	}
+
+	return net.JoinHostPort(host, defaultShellPort)
+}
+
+// knownHostKeyAlgorithms returns the types of the keys that callback knows for hostport, or for the host
+// itself if there aren't any for that port. It returns nil if the host is unknown, leaving the defaults.
+func knownHostKeyAlgorithms(callback ssh.HostKeyCallback, hostport string) []string {
+	// Checking a key that can't match makes the callback return every key it knows for the host.
+	probe, err := ssh.NewPublicKey(ed25519.PublicKey(make([]byte, ed25519.PublicKeySize)))
+	if err != nil {
+		return nil
+	}
 
-		return config, nil
+	for _, address := range []string{hostport, withSSHPort(hostport)} {
+		var keyErr *knownhosts.KeyError
+		if err := callback(address, &net.TCPAddr{}, probe); !errors.As(err, &keyErr) || len(keyErr.Want) == 0 {
+			continue
+		}
+
+		var algorithms []string
+		for _, k := range keyErr.Want {
+			algorithms = append(algorithms, k.Key.Type())
+		}
+
+		return algorithms
+	}
+
+	return nil
+}
+
+// hostWithPort appends the given port to host if one wasn't specified. This also takes care of IPv6
+// addresses, both bare ("2001:db8::1") and in brackets ("[2001:db8::1]"), which would otherwise be
+// mistaken for a host:port pair.
+func hostWithPort(host, port string) string {
+	if _, _, err := net.SplitHostPort(host); err == nil {
+		return host
+	}
+
+	if strings.HasPrefix(host, "[") && strings.HasSuffix(host, "]") {
+		host = host[1 : len(host)-1]
 	}
 
-	return config, errors.New("no credentials/keys available")
+	return net.JoinHostPort(host, port)
 }
 
 // NewSession establishes a new connection to a Junos device that we will use
@@ -198,28 +580,117 @@ func genSSHClientConfig(auth *AuthMethod) (*ssh.ClientConfig, error) {
 //
 // Please view the package documentation for AuthMethod on how to use these methods.
 //
+// The host can optionally include the port to connect to, i.e. "srx.company.com:22" or
+// "[2001:db8::1]:2222". The port is the SSH transport port, and the netconf subsystem is
+// requested on top of that connection. If no port is given, the default netconf port 830 is used.
+//
 // NOTE: most users should use this function, instead of the other NewSession* functions
 func NewSession(host string, auth *AuthMethod) (*Junos, error) {
-	clientConfig, err := genSSHClientConfig(auth)
+	clientConfig, err := genSSHClientConfig(host, auth)
+	if err != nil {
+		return nil, err
+	}
+
+	return newSession(host, clientConfig, auth.Shell)
+}
+
+// NewSessionWithRetry is the same as NewSession, but makes up to the given number of attempts to connect
+// to the device, waiting for backoff between each one. This is useful when waiting for a device to come
+// back up after a reboot or upgrade. If every attempt fails, the error from the last one is returned.
+func NewSessionWithRetry(host string, auth *AuthMethod, attempts int, backoff time.Duration) (*Junos, error) {
+	return NewSessionWithRetryContext(context.Background(), host, auth, attempts, backoff)
+}
+
+// NewSessionWithRetryContext is the same as NewSessionWithRetry, but stops retrying once the given context
+// is canceled or its deadline passes, returning ctx.Err() along with the last connection error.
+func NewSessionWithRetryContext(ctx context.Context, host string, auth *AuthMethod, attempts int, backoff time.Duration) (*Junos, error) {
+	if attempts < 1 {
+		return nil, errors.New("the number of attempts must be at least 1")
+	}
+
+	clientConfig, err := genSSHClientConfig(host, auth)
 	if err != nil {
 		return nil, err
 	}
 
-	return NewSessionWithConfig(host, clientConfig)
+	for i := 0; i < attempts; i++ {
+		if i > 0 {
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return nil, fmt.Errorf("%w (last error: %v)", ctx.Err(), err)
+			}
+		}
+
+		var j *Junos
+		j, err = newSession(host, clientConfig, auth.Shell)
+		if err == nil {
+			return j, nil
+		}
+
+		if j != nil {
+			j.Session.Transport.Close()
+		}
+	}
+
+	return nil, err
 }
 
 // NewSessionWithConfig establishes a new connection to a Junos device that we will use
 // to run our commands against.
 //
 // This is especially useful if you need to customize the SSH connection beyond
-// what's supported in NewSession().
+// what's supported in NewSession(). A connection timeout can be set using the Timeout
+// field of the ssh.ClientConfig.
 func NewSessionWithConfig(host string, clientConfig *ssh.ClientConfig) (*Junos, error) {
-	s, err := netconf.DialSSH(host, clientConfig)
+	return newSession(host, clientConfig, false)
+}
+
+// newSession connects to the device over SSH, either using the netconf subsystem or, if shell is true, the CLI.
+func newSession(host string, clientConfig *ssh.ClientConfig, shell bool) (*Junos, error) {
+	s, err := dial(host, clientConfig, shell)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to %s - %w", host, err)
+	}
+
+	j, err := NewSessionFromNetconf(s)
+	j.host = host
+	j.user = clientConfig.User
+	j.clientConfig = clientConfig
+	j.shell = shell
+
+	return j, err
+}
+
+// dial establishes the NETCONF session to the device, using the netconf subsystem or, if shell is true, the CLI.
+func dial(host string, clientConfig *ssh.ClientConfig, shell bool) (*netconf.Session, error) {
+	if !shell {
+		return netconf.DialSSH(hostWithPort(host, defaultPort), clientConfig)
+	}
+
+	return dialShell(hostWithPort(host, defaultShellPort), clientConfig)
+}
+
+// NewSessionTLS establishes a new connection to a Junos device over TLS, instead of SSH, using the given TLS
+// configuration. This is useful in environments that use mutual TLS for device management; provide the client
+// certificate in tlsConfig.Certificates. The host can optionally include the port, otherwise the NETCONF over
+// TLS port, 6513, is used. Note that sessions created this way can't transfer files, or be reconnected.
+func NewSessionTLS(host string, tlsConfig *tls.Config) (*Junos, error) {
+	conn, err := tls.Dial("tcp", hostWithPort(host, defaultTLSPort), tlsConfig)
 	if err != nil {
-		return nil, errors.New(fmt.Sprintf("error connecting to %s - %s", host, err))
+		return nil, fmt.Errorf("error connecting to %s - %w", host, err)
+	}
+
+	s := netconf.NewSession(newFramedTransport(conn))
+	if len(s.ServerCapabilities) == 0 {
+		conn.Close()
+		return nil, fmt.Errorf("error connecting to %s - the device did not start a NETCONF session over TLS", host)
 	}
 
-	return NewSessionFromNetconf(s)
+	j, err := NewSessionFromNetconf(s)
+	j.host = host
+
+	return j, err
 }
 
 // NewSessionFromNetConn uses an existing net.Conn to establish a netconf.Session
@@ -230,10 +701,14 @@ func NewSessionFromNetConn(host string, nc net.Conn, clientConfig *ssh.ClientCon
 	s, err := netconf.NewSSHSession(nc, clientConfig)
 
 	if err != nil {
-		return nil, errors.New(fmt.Sprintf("error connecting to %s - %s", host, err))
+		return nil, fmt.Errorf("error connecting to %s - %w", host, err)
 	}
 
-	return NewSessionFromNetconf(s)
+	j, err := NewSessionFromNetconf(s)
+	j.host = host
+	j.user = clientConfig.User
+
+	return j, err
 }
 
 // NewSessionFromNetconf uses an existing netconf.Session to run our commands against
@@ -256,295 +731,880 @@ func (j *Junos) GatherFacts() error {
 	if j == nil {
 		return errors.New("attempt to call GatherFacts on nil Junos object")
 	}
-	s := j.Session
-	rex := regexp.MustCompile(`^.*\[(.*)\]`)
 
-	reply, err := s.Exec(netconf.RawMethod(rpcVersion))
+	versions, err := j.Versions()
 	if err != nil {
 		return err
 	}
 
-	if reply.Errors != nil {
-		for _, m := range reply.Errors {
-			return errors.New(m.Message)
-		}
+	res := make([]RoutingEngine, 0, len(versions))
+	for _, v := range versions {
+		res = append(res, RoutingEngine{Model: v.Model, Version: v.Version})
+	}
+
+	j.Hostname = versions[0].Hostname
+	j.RoutingEngines = len(versions)
+	j.Platform = res
+	j.CommitTimeout = 0
+	return nil
+}
+
+// Versions returns the hostname, model and Junos version of each routing engine on the device, the same
+// as "show version invoke-on all-routing-engines." A device with a single routing engine returns
+// a slice with one entry.
+func (j *Junos) Versions() ([]RoutingEngineVersion, error) {
+	reply, err := j.exec(rpcVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := joinRPCErrors(reply); err != nil {
+		return nil, err
 	}
 
 	formatted := strings.Replace(reply.Data, "\n", "", -1)
 
 	if strings.Contains(reply.Data, "multi-routing-engine-results") {
 		var facts versionRouteEngines
-		err = xml.Unmarshal([]byte(formatted), &facts)
-		if err != nil {
-			return err
+		if err := xml.Unmarshal([]byte(formatted), &facts); err != nil {
+			return nil, &ParseError{Raw: formatted, Err: fmt.Errorf("failed to parse version information - %w", err)}
 		}
 
-		numRE := len(facts.RE)
-		hostname := facts.RE[0].Hostname
-		res := make([]RoutingEngine, 0, numRE)
-
-		for i := 0; i < numRE; i++ {
-			version := rex.FindStringSubmatch(facts.RE[i].PackageInfo[0].SoftwareVersion[0])
-			model := strings.ToUpper(facts.RE[i].Platform)
+		if len(facts.Items) == 0 {
+			return nil, errors.New("no version information was returned by the device")
+		}
 
-			switch len(version) {
-			case 1:
-				res = append(res, RoutingEngine{Model: model, Version: version[0]})
-			case 2:
-				res = append(res, RoutingEngine{Model: model, Version: version[1]})
-			default:
-			}
+		res := make([]RoutingEngineVersion, 0, len(facts.Items))
+		for _, item := range facts.Items {
+			v := item.Software.version()
+			v.Name = item.Name
+			res = append(res, v)
 		}
 
-		j.Hostname = hostname
-		j.RoutingEngines = numRE
-		j.Platform = res
-		j.CommitTimeout = 0
-		return nil
+		return res, nil
 	}
 
 	var facts versionRouteEngine
-	err = xml.Unmarshal([]byte(formatted), &facts)
-	if err != nil {
-		return err
+	if err := xml.Unmarshal([]byte(formatted), &facts); err != nil {
+		return nil, &ParseError{Raw: formatted, Err: fmt.Errorf("failed to parse version information - %w", err)}
 	}
 
-	// res := make([]RoutingEngine, 0)
-	var res []RoutingEngine
-	hostname := facts.Hostname
-	version := rex.FindStringSubmatch(facts.PackageInfo[0].SoftwareVersion[0])
-	model := strings.ToUpper(facts.Platform)
-	res = append(res, RoutingEngine{Model: model, Version: version[1]})
-
-	j.Hostname = hostname
-	j.RoutingEngines = 1
-	j.Platform = res
-	j.CommitTimeout = 0
-	return nil
-}
-
-// Close disconnects our session to the device.
-func (j *Junos) Close() {
-	j.Session.Transport.Close()
+	return []RoutingEngineVersion{facts.version()}, nil
 }
 
-// Command executes any operational mode command, such as "show" or "request." If you wish to return the results
-// of the command, specify the format, which must be "text" or "xml" as the second parameter (optional).
-func (j *Junos) Command(cmd string, format ...string) (string, error) {
-	var command string
-	command = fmt.Sprintf(rpcCommand, cmd)
+// GetFacts returns the hostname, model, software version, serial number and uptime of the device. Unlike
+// GatherFacts, it doesn't change the Hostname, Platform or CommitTimeout of the session.
+func (j *Junos) GetFacts() (*Facts, error) {
+	versions, err := j.Versions()
+	if err != nil {
+		return nil, err
+	}
 
-	if len(format) > 0 && format[0] == "xml" {
-		command = fmt.Sprintf(rpcCommandXML, cmd)
+	facts := &Facts{
+		Hostname: versions[0].Hostname,
+		Model:    versions[0].Model,
+		Version:  versions[0].Version,
 	}
 
-	reply, err := j.Session.Exec(netconf.RawMethod(command))
+	reply, err := j.exec(rpcFactsChassis)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	if reply.Errors != nil {
-		for _, m := range reply.Errors {
-			return "", errors.New(m.Message)
-		}
+	if err := joinRPCErrors(reply); err != nil {
+		return nil, err
 	}
 
-	if reply.Data == "" {
-		return "", errors.New("no output available - please check the syntax of your command")
-	}
+	formatted := strings.Replace(reply.Data, "\n", "", -1)
 
-	if len(format) > 0 && format[0] == "text" {
-		var output commandXML
-		err = xml.Unmarshal([]byte(reply.Data), &output)
-		if err != nil {
-			return "", err
+	if strings.Contains(reply.Data, "multi-routing-engine-results") {
+		var hardware hardwareRouteEngines
+		if err := xml.Unmarshal([]byte(formatted), &hardware); err != nil {
+			return nil, &ParseError{Raw: formatted, Err: fmt.Errorf("failed to parse chassis inventory - %w", err)}
 		}
 
-		return output.Config, nil
-	}
+		if len(hardware.RE) > 0 {
+			facts.SerialNumber = hardware.RE[0].Serial
+		}
+	} else {
+		var hardware hardwareRouteEngine
+		if err := xml.Unmarshal([]byte(formatted), &hardware); err != nil {
+			return nil, &ParseError{Raw: formatted, Err: fmt.Errorf("failed to parse chassis inventory - %w", err)}
+		}
 
-	return reply.Data, nil
-}
+		facts.SerialNumber = hardware.Serial
+	}
 
-// CommitHistory gathers all the information about the previous 5 commits.
-func (j *Junos) CommitHistory() (*CommitHistory, error) {
-	var history CommitHistory
-	reply, err := j.Session.Exec(netconf.RawMethod(rpcCommitHistory))
+	reply, err = j.exec(rpcFactsRE)
 	if err != nil {
 		return nil, err
 	}
 
-	if reply.Errors != nil {
-		for _, m := range reply.Errors {
-			return nil, errors.New(m.Message)
-		}
+	if err := joinRPCErrors(reply); err != nil {
+		return nil, err
 	}
 
-	if reply.Data == "" {
-		return nil, errors.New("could not load commit history")
+	formatted = strings.Replace(reply.Data, "\n", "", -1)
+	var uptime []string
+
+	if strings.Contains(reply.Data, "multi-routing-engine-results") {
+		var re uptimeRouteEngines
+		if err := xml.Unmarshal([]byte(formatted), &re); err != nil {
+			return nil, &ParseError{Raw: formatted, Err: fmt.Errorf("failed to parse routing engine information - %w", err)}
+		}
+
+		uptime = re.UpTime
+	} else {
+		var re uptimeRouteEngine
+		if err := xml.Unmarshal([]byte(formatted), &re); err != nil {
+			return nil, &ParseError{Raw: formatted, Err: fmt.Errorf("failed to parse routing engine information - %w", err)}
+		}
+
+		uptime = re.UpTime
 	}
 
-	formatted := strings.Replace(reply.Data, "\n", "", -1)
-	err = xml.Unmarshal([]byte(formatted), &history)
-	if err != nil {
-		return nil, err
+	if len(uptime) > 0 {
+		facts.Uptime = strings.TrimSpace(uptime[0])
 	}
 
-	return &history, nil
+	return facts, nil
 }
 
-// Commit commits the configuration.
-func (j *Junos) Commit() error {
-	var errs commitResults
-	reply, err := j.Session.Exec(netconf.RawMethod(rpcCommit))
-	if err != nil {
-		return err
+// Reconnect tears down the current connection to the device, and establishes a new one using
+// the host and credentials the session was originally created with. This is useful for
+// recovering long-lived sessions that were dropped due to idle timeouts or network issues.
+//
+// Only sessions created with NewSession or NewSessionWithConfig can be reconnected.
+func (j *Junos) Reconnect() error {
+	if j.clientConfig == nil {
+		return errors.New("this session was not created with NewSession or NewSessionWithConfig, and cannot be reconnected")
 	}
 
-	if reply.Errors != nil {
-		for _, m := range reply.Errors {
-			return errors.New(m.Message)
-		}
+	if j.Session != nil {
+		// The connection is most likely already dead, so there's nothing to do about an error here.
+		j.Session.Transport.Close()
 	}
 
-	formatted := strings.Replace(reply.Data, "\n", "", -1)
-	err = xml.Unmarshal([]byte(formatted), &errs)
+	s, err := dial(j.host, j.clientConfig, j.shell)
 	if err != nil {
-		return err
+		return fmt.Errorf("error connecting to %s - %w", j.host, err)
 	}
 
-	if errs.Errors != nil {
-		for _, m := range errs.Errors {
-			return errors.New(strings.Trim(m.Message, "[\r\n]"))
-		}
-	}
-
-	if j.CommitTimeout > 0 {
-		time.Sleep(j.CommitTimeout * time.Second)
-	}
+	j.Session = s
+	j.locked = false
 
 	return nil
 }
 
-// CommitAt commits the configuration at the specified time. Time must be in 24-hour HH:mm format.
-// Specifying a commit message is optional.
-func (j *Junos) CommitAt(time string, message ...string) error {
-	var errs commitResults
-	command := fmt.Sprintf(rpcCommitAt, time)
-
-	if len(message) > 0 {
-		command = fmt.Sprintf(rpcCommitAtLog, time, message[0])
-	}
-
-	reply, err := j.Session.Exec(netconf.RawMethod(command))
+// Ping checks that the session to the device is still alive, by sending it a lightweight RPC
+// (the equivalent of "show system uptime"). It returns nil if the device replied.
+func (j *Junos) Ping() error {
+	reply, err := j.exec(rpcUptime)
 	if err != nil {
 		return err
 	}
 
-	if reply.Errors != nil {
-		for _, m := range reply.Errors {
-			return errors.New(m.Message)
-		}
-	}
-
-	formatted := strings.Replace(reply.Data, "\n", "", -1)
-	err = xml.Unmarshal([]byte(formatted), &errs)
-	if err != nil {
+	if err := joinRPCErrors(reply); err != nil {
 		return err
 	}
 
-	if errs.Errors != nil {
-		for _, m := range errs.Errors {
-			return errors.New(strings.Trim(m.Message, "[\r\n]"))
-		}
+	return nil
+}
+
+// Capabilities returns the capabilities the device advertised when the netconf session was
+// established. No RPC is sent to the device.
+func (j *Junos) Capabilities() []string {
+	return j.Session.ServerCapabilities
+}
+
+// Close disconnects our session to the device, and returns any error from closing the
+// connection. The session must not be used after it's closed, unless it is re-established
+// using Reconnect.
+func (j *Junos) Close() error {
+	return j.Session.Transport.Close()
+}
+
+// Command executes any operational mode command, such as "show" or "request." If you wish to return the results
+// of the command, specify the format, which must be "text", "xml" or "json" as the second parameter (optional).
+// Any other format is run as a text command, and the raw reply is returned. The command may include pipe
+// modifiers, such as "show route | match 10.0.0.0," and any characters in it are escaped before it is sent.
+func (j *Junos) Command(cmd string, format ...string) (string, error) {
+	return j.CommandContext(context.Background(), cmd, format...)
+}
+
+// commandRPC returns the <command> RPC that runs cmd, with its output in the given format (text by default).
+// Cmd is escaped, so that characters such as "<" in pipe modifiers don't break the RPC.
+func commandRPC(cmd string, format ...string) string {
+	cmd = escapeXML(cmd)
+
+	if len(format) > 0 {
+		switch format[0] {
+		case "xml":
+			return fmt.Sprintf(rpcCommandXML, cmd)
+		case "json":
+			return fmt.Sprintf(rpcCommandJSON, cmd)
+		}
+	}
+
+	return fmt.Sprintf(rpcCommand, cmd)
+}
+
+// CommandContext is the same as Command, but gives up waiting on the device once the given
+// context is canceled or its deadline passes, returning ctx.Err(). Because the device may still
+// send its reply, the session is closed when this happens and must not be used afterwards.
+func (j *Junos) CommandContext(ctx context.Context, cmd string, format ...string) (string, error) {
+	reply, err := j.execContext(ctx, commandRPC(cmd, format...))
+	if err != nil {
+		return "", err
+	}
+
+	if err := joinRPCErrors(reply); err != nil {
+		return "", err
+	}
+
+	if reply.Data == "" {
+		if j.allowEmpty {
+			return "", nil
+		}
+
+		return "", errors.New("no output available - please check the syntax of your command")
+	}
+
+	if len(format) > 0 {
+		switch format[0] {
+		case "text":
+			var output commandXML
+			err = xml.Unmarshal([]byte(reply.Data), &output)
+			if err != nil {
+				return "", &ParseError{Raw: reply.Data, Err: fmt.Errorf("failed to parse command output - %w", err)}
+			}
+
+			return output.Config, nil
+		case "json":
+			return strings.TrimSpace(reply.Data), nil
+		}
+	}
+
+	return reply.Data, nil
+}
+
+// SetRequestTimeout makes every RPC sent after it's called give up after the given duration, as if it had been
+// sent with a context that has a timeout (see CommandContext). When an RPC times out, the session is closed, and
+// Reconnect must be used before sending anything else. Methods that take a context, such as CommandContext and
+// SupportInfo, are only bound by their context. A duration of 0 means RPCs never time out, which is the default.
+func (j *Junos) SetRequestTimeout(d time.Duration) {
+	j.timeout = d
+}
+
+// SetAllowEmptyOutput controls what Command, CommandContext and View do when the device doesn't return any
+// output. By default, an error is returned saying that no output is available. When allow is true, an empty
+// string (or, for View, an empty set of results) and no error is returned instead.
+func (j *Junos) SetAllowEmptyOutput(allow bool) {
+	j.allowEmpty = allow
+}
+
+// Commands runs each of the given operational mode commands in order over the current session, and
+// returns their output in a slice matching cmds. Format is the same as for Command (optional). If a
+// command fails, the remaining commands are not run, and the output of the commands that succeeded
+// is returned along with an error naming the index of the one that failed.
+func (j *Junos) Commands(cmds []string, format ...string) ([]string, error) {
+	output := make([]string, 0, len(cmds))
+
+	for i, cmd := range cmds {
+		out, err := j.Command(cmd, format...)
+		if err != nil {
+			return output, fmt.Errorf("command %d (%s) failed - %w", i, cmd, err)
+		}
+
+		output = append(output, out)
+	}
+
+	return output, nil
+}
+
+// RunOpScript runs the given op script, which must be stored on the device in /var/db/scripts/op and
+// enabled under "system scripts op," the same as "op <name>." Args are the arguments passed to the script as
+// name/value pairs, and are sent in order of their names. If the script produces text output (i.e. with
+// jcs:output), the text is returned, otherwise the XML returned by the script is.
+func (j *Junos) RunOpScript(name string, args map[string]string) (string, error) {
+	var arguments string
+
+	if name == "" {
+		return "", errors.New("the name of the op script must be given")
+	}
+
+	if len(args) > 0 {
+		names := make([]string, 0, len(args))
+		for n := range args {
+			names = append(names, n)
+		}
+
+		sort.Strings(names)
+
+		arguments = "<arguments>"
+		for _, n := range names {
+			arguments += fmt.Sprintf("<argument><name>%s</name><value>%s</value></argument>", escapeXML(n), escapeXML(args[n]))
+		}
+		arguments += "</arguments>"
+	}
+
+	reply, err := j.exec(fmt.Sprintf(rpcOpScript, escapeXML(name), arguments))
+	if err != nil {
+		return "", err
+	}
+
+	if err := joinRPCErrors(reply); err != nil {
+		return "", err
+	}
+
+	data := strings.TrimSpace(reply.Data)
+	if !strings.HasPrefix(data, "<output") {
+		return data, nil
+	}
+
+	output, err := textOutput(data)
+	if err != nil {
+		return "", &ParseError{Raw: reply.Data, Err: fmt.Errorf("failed to parse op script output - %w", err)}
+	}
+
+	return output, nil
+}
+
+// textOutput returns the text in every element of the given reply data, i.e. the contents of <output>, with
+// any escaped characters such as "&lt;" decoded. Text outside of the elements, between replies, is ignored.
+func textOutput(data string) (string, error) {
+	var text strings.Builder
+	depth := 0
+
+	d := xml.NewDecoder(strings.NewReader(data))
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return "", err
+		}
+
+		switch t := t.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			depth--
+		case xml.CharData:
+			if depth > 0 {
+				text.Write(t)
+			}
+		}
+	}
+
+	return text.String(), nil
+}
+
+// SystemLog returns the last given number of lines of the messages log file on the device, the same as
+// "show log messages | last <lines>." If lines is 0, the last 100 lines are returned.
+func (j *Junos) SystemLog(lines int) ([]string, error) {
+	if lines < 0 {
+		return nil, errors.New("the number of lines can't be negative")
+	}
+
+	if lines == 0 {
+		lines = 100
+	}
+
+	output, err := j.Command(fmt.Sprintf("show log messages | last %d", lines), "text")
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []string
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimRight(line, "\r"); strings.TrimSpace(line) != "" {
+			messages = append(messages, line)
+		}
+	}
+
+	return messages, nil
+}
+
+// SetLogger logs every RPC sent to the device, and the raw data of each reply, to the given logger. This
+// is useful when debugging why a device rejects a request. Passing nil turns logging off, which is the default.
+func (j *Junos) SetLogger(l *log.Logger) {
+	j.logger = l
+}
+
+// exec executes the given RPC, giving up once the timeout set with SetRequestTimeout has passed.
+func (j *Junos) exec(rpc string) (*netconf.RPCReply, error) {
+	if j.timeout > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), j.timeout)
+		defer cancel()
+
+		return j.execContext(ctx, rpc)
+	}
+
+	return j.send(rpc)
+}
+
+// send sends the RPC to the device and waits for the reply, logging both if a logger has been set.
+func (j *Junos) send(rpc string) (*netconf.RPCReply, error) {
+	if j.logger != nil {
+		j.logger.Printf("rpc: %s", rpc)
+	}
+
+	reply, err := j.Session.Exec(netconf.RawMethod(rpc))
+
+	// Exec only returns the first error in the reply, so replace it with all of them.
+	var rpcErr *netconf.RPCError
+	if errors.As(err, &rpcErr) && reply != nil {
+		if joined := joinRPCErrors(reply); joined != nil {
+			err = joined
+		}
+	}
+
+	if j.logger != nil {
+		if reply != nil {
+			j.logger.Printf("reply: %s", reply.Data)
+		}
+
+		if err != nil {
+			j.logger.Printf("error: %v", err)
+		}
+	}
+
+	return reply, err
+}
+
+// SupportInfo returns the output of "request support information," which is typically attached to JTAC
+// cases. Gathering it can take several minutes, so the given context can be used to give up waiting; see
+// CommandContext for what happens to the session when the context is done.
+func (j *Junos) SupportInfo(ctx context.Context) (string, error) {
+	var output commandXML
+	reply, err := j.execContext(ctx, rpcSupportInfo)
+	if err != nil {
+		return "", err
+	}
+
+	if err := joinRPCErrors(reply); err != nil {
+		return "", err
+	}
+
+	if err := xml.Unmarshal([]byte(reply.Data), &output); err != nil {
+		return "", &ParseError{Raw: reply.Data, Err: fmt.Errorf("failed to parse support information - %w", err)}
+	}
+
+	return output.Config, nil
+}
+
+// execContext executes the given RPC, returning early with ctx.Err() if the context is done before
+// the device replies. The session is closed in that case, since the reply to the abandoned RPC
+// would otherwise be read as the reply to the next one.
+func (j *Junos) execContext(ctx context.Context, rpc string) (*netconf.RPCReply, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	type result struct {
+		reply *netconf.RPCReply
+		err   error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		reply, err := j.send(rpc)
+		done <- result{reply, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.reply, r.err
+	case <-ctx.Done():
+		j.Session.Transport.Close()
+		return nil, ctx.Err()
+	}
+}
+
+// Uptime returns the current time on the device, when it booted and was last configured, and how long it
+// has been up, the same as "show system uptime." On devices with multiple routing engines, or clustered SRX's,
+// the information is taken from the first one. To see why a routing engine last rebooted, use RoutingEngineStatus.
+func (j *Junos) Uptime() (*SystemUptime, error) {
+	var result systemUptimeResult
+	reply, err := j.exec(rpcUptime)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := joinRPCErrors(reply); err != nil {
+		return nil, err
+	}
+
+	formatted := strings.Replace(reply.Data, "\n", "", -1)
+
+	if strings.Contains(reply.Data, "multi-routing-engine-results") {
+		var uptimes systemUptimes
+		if err := xml.Unmarshal([]byte(formatted), &uptimes); err != nil {
+			return nil, &ParseError{Raw: formatted, Err: fmt.Errorf("failed to parse uptime information - %w", err)}
+		}
+
+		if len(uptimes.RE) == 0 {
+			return nil, errors.New("no uptime information was returned by the device")
+		}
+
+		result = uptimes.RE[0]
+	} else {
+		if err := xml.Unmarshal([]byte(formatted), &result); err != nil {
+			return nil, &ParseError{Raw: formatted, Err: fmt.Errorf("failed to parse uptime information - %w", err)}
+		}
+	}
+
+	uptime := &SystemUptime{
+		CurrentTime:          result.CurrentTime.time(),
+		SystemBootedTime:     result.BootedTime.time(),
+		ProtocolsStartedTime: result.ProtocolsStarted.time(),
+		LastConfiguredTime:   result.LastConfigured.time(),
+		LastConfiguredBy:     result.LastConfiguredBy,
+		Uptime:               time.Duration(result.BootedLength.Seconds) * time.Second,
+	}
+
+	if uptime.Uptime == 0 && !uptime.SystemBootedTime.IsZero() && !uptime.CurrentTime.IsZero() {
+		uptime.Uptime = uptime.CurrentTime.Sub(uptime.SystemBootedTime)
+	}
+
+	return uptime, nil
+}
+
+// RPC sends the given raw XML RPC to the device, such as "<get-system-alarm-information/>,"
+// and returns the data from the reply. This is useful for any RPC that isn't covered by the
+// other functions in this package.
+func (j *Junos) RPC(rpc string) (string, error) {
+	reply, err := j.exec(rpc)
+	if err != nil {
+		return "", err
+	}
+
+	if err := joinRPCErrors(reply); err != nil {
+		return "", err
+	}
+
+	return reply.Data, nil
+}
+
+// GetYANG sends a NETCONF <get> using the given subtree filter, and returns the XML data from the reply.
+// This allows you to retrieve state data using standard models such as OpenConfig, e.g.:
+//
+// j.GetYANG("<interfaces xmlns=\"http://openconfig.net/yang/interfaces\"/>")
+//
+// If the device does not support the model, the error from the device is returned.
+func (j *Junos) GetYANG(filter string) (string, error) {
+	return j.RPC(fmt.Sprintf(rpcGetFilter, filter))
+}
+
+// Subscribe creates a NETCONF notification subscription to the given event stream (or the default
+// "NETCONF" stream, if stream is empty), and returns a channel that delivers the raw XML of each
+// notification as it arrives. The channel is closed when the session ends.
+//
+// Once subscribed, the session is dedicated to the notifications and can't be used to run any other
+// commands. To stop the subscription, call Close on the session, and keep reading from the channel
+// until it is closed.
+func (j *Junos) Subscribe(stream string) (<-chan string, error) {
+	var filter string
+	if stream != "" {
+		filter = fmt.Sprintf("<stream>%s</stream>", escapeXML(stream))
+	}
+
+	reply, err := j.exec(fmt.Sprintf(rpcSubscribe, filter))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := joinRPCErrors(reply); err != nil {
+		return nil, err
+	}
+
+	events := make(chan string)
+	go func() {
+		defer close(events)
+
+		for {
+			msg, err := j.Session.Transport.Receive()
+			if err != nil {
+				return
+			}
+
+			events <- strings.TrimSpace(string(msg))
+		}
+	}()
+
+	return events, nil
+}
+
+// CommitHistory gathers all the information about the previous commits, the same as "show system commit,"
+// including the user, client and comment of each one, and whether a "commit confirmed" is waiting to be confirmed.
+func (j *Junos) CommitHistory() (*CommitHistory, error) {
+	var history CommitHistory
+	reply, err := j.exec(rpcCommitHistory)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := joinRPCErrors(reply); err != nil {
+		return nil, err
+	}
+
+	if reply.Data == "" {
+		return nil, errors.New("could not load commit history")
+	}
+
+	formatted := strings.Replace(reply.Data, "\n", "", -1)
+	err = xml.Unmarshal([]byte(formatted), &history)
+	if err != nil {
+		return nil, &ParseError{Raw: formatted, Err: fmt.Errorf("failed to parse commit history - %w", err)}
+	}
+
+	// The device notes an unconfirmed "commit confirmed" in the log of the latest entry, i.e. "commit confirmed,
+	// rollback in 10mins." Only that exact form is matched, since the comment is free text entered by the user.
+	// Confirming it makes a new commit, so older entries are never pending.
+	pendingRegex := regexp.MustCompile(`^commit confirmed, rollback in \d+\s*mins?$`)
+	if len(history.Entries) > 0 && pendingRegex.MatchString(strings.TrimSpace(history.Entries[0].Log)) {
+		history.PendingConfirm = true
+	}
+
+	return &history, nil
+}
+
+// checkCommitResults parses the <commit-results> returned from a commit, and returns
+// every error found in it, including those reported by each routing engine. Each error includes the configuration path and statement
+// that failed, when the device provides them. Warnings are ignored, since the commit
+// still succeeds.
+func checkCommitResults(data string) error {
+	var results commitResults
+	formatted := strings.Replace(data, "\n", "", -1)
+	err := xml.Unmarshal([]byte(formatted), &results)
+	if err != nil {
+		return &ParseError{Raw: formatted, Err: fmt.Errorf("failed to parse commit results - %w", err)}
+	}
+
+	return joinCommitErrors(append(results.Errors, results.REErrors...))
+}
+
+// checkLoadResults parses the <load-configuration-results> returned from loading a
+// configuration, and returns every statement that was rejected by the device.
+func checkLoadResults(data string) error {
+	var results loadResults
+	formatted := strings.Replace(data, "\n", "", -1)
+	err := xml.Unmarshal([]byte(formatted), &results)
+	if err != nil {
+		return &ParseError{Raw: formatted, Err: fmt.Errorf("failed to parse load results - %w", err)}
+	}
+
+	return joinCommitErrors(results.Errors)
+}
+
+// joinRPCErrors combines every error in the reply into a single error, one message per line, since the device
+// often returns several related errors at once. Warnings are skipped, since the RPC still succeeded. It returns
+// nil if the reply doesn't contain any errors.
+func joinRPCErrors(reply *netconf.RPCReply) error {
+	var messages []string
+	for _, m := range reply.Errors {
+		if strings.TrimSpace(m.Severity) != "error" {
+			continue
+		}
+
+		messages = append(messages, strings.TrimSpace(m.Message))
+	}
+
+	if len(messages) > 0 {
+		return errors.New(strings.Join(messages, "\n"))
+	}
+
+	return nil
+}
+
+// joinCommitErrors combines the given errors into a single error, skipping any warnings.
+func joinCommitErrors(errs []commitError) error {
+	var messages []string
+	for _, m := range errs {
+		if strings.Trim(m.Severity, "[\r\n]") == "warning" {
+			continue
+		}
+
+		message := strings.Trim(m.Message, "[\r\n]")
+		if m.Path != "" {
+			message = fmt.Sprintf("[%s]\n    %s\nError: %s", strings.Trim(m.Path, "[\r\n]"), strings.Trim(m.Element, "[\r\n]"), message)
+		}
+
+		messages = append(messages, message)
+	}
+
+	if len(messages) > 0 {
+		return errors.New(strings.Join(messages, "\n"))
 	}
 
 	return nil
 }
 
+// SetDryRun turns dry run mode on or off for the session. In dry run mode, configurations are still
+// loaded into the candidate configuration as usual, but every commit (Commit, CommitAt, CommitComment,
+// CommitConfirm, CommitFull and CommitSync, as well as Rollback) sends a commit check,
+// <commit-configuration><check/></commit-configuration>, instead of committing. This validates the
+// changes against the device without activating them. The checked changes are left in the candidate
+// configuration, so use DiscardChanges to clear them afterwards; ApplyConfig does this for you.
+func (j *Junos) SetDryRun(dryRun bool) {
+	j.dryRun = dryRun
+}
+
+// Commit commits the configuration. If the commit fails, the returned error contains
+// the message for each statement that failed, along with its configuration path.
+func (j *Junos) Commit() error {
+	if j.dryRun {
+		return j.CommitCheck()
+	}
+
+	reply, err := j.exec(rpcCommit)
+	if err != nil {
+		return err
+	}
+
+	if err := joinRPCErrors(reply); err != nil {
+		return err
+	}
+
+	if err := checkCommitResults(reply.Data); err != nil {
+		return err
+	}
+
+	if j.CommitTimeout > 0 {
+		time.Sleep(j.CommitTimeout * time.Second)
+	}
+
+	return nil
+}
+
+// CommitAt commits the configuration at the specified time. Time must be in 24-hour HH:mm format.
+// Specifying a commit message is optional.
+func (j *Junos) CommitAt(time string, message ...string) error {
+	if j.dryRun {
+		return j.CommitCheck()
+	}
+
+	command := fmt.Sprintf(rpcCommitAt, time)
+
+	if len(message) > 0 {
+		command = fmt.Sprintf(rpcCommitAtLog, time, escapeXML(message[0]))
+	}
+
+	reply, err := j.exec(command)
+	if err != nil {
+		return err
+	}
+
+	if err := joinRPCErrors(reply); err != nil {
+		return err
+	}
+
+	return checkCommitResults(reply.Data)
+}
+
 // CommitCheck checks the configuration for syntax errors, but does not commit any changes.
+// It returns nil if the candidate configuration is valid, otherwise the error contains every
+// validation message returned by the device.
 func (j *Junos) CommitCheck() error {
-	var errs commitResults
-	reply, err := j.Session.Exec(netconf.RawMethod(rpcCommitCheck))
+	reply, err := j.exec(rpcCommitCheck)
 	if err != nil {
 		return err
 	}
 
-	if reply.Errors != nil {
-		for _, m := range reply.Errors {
-			return errors.New(m.Message)
-		}
+	if err := joinRPCErrors(reply); err != nil {
+		return err
 	}
 
-	formatted := strings.Replace(reply.Data, "\n", "", -1)
-	err = xml.Unmarshal([]byte(formatted), &errs)
+	return checkCommitResults(reply.Data)
+}
+
+// CommitComment commits the configuration with the given comment, which is shown in
+// the output of "show system commit."
+func (j *Junos) CommitComment(comment string) error {
+	if j.dryRun {
+		return j.CommitCheck()
+	}
+
+	command := fmt.Sprintf(rpcCommitLog, escapeXML(comment))
+	reply, err := j.exec(command)
 	if err != nil {
 		return err
 	}
 
-	if errs.Errors != nil {
-		for _, m := range errs.Errors {
-			return errors.New(strings.Trim(m.Message, "[\r\n]"))
-		}
+	if err := joinRPCErrors(reply); err != nil {
+		return err
 	}
 
-	return nil
+	return checkCommitResults(reply.Data)
 }
 
-// CommitConfirm rolls back the configuration after the delayed minutes.
-func (j *Junos) CommitConfirm(delay int) error {
-	var errs commitResults
-	command := fmt.Sprintf(rpcCommitConfirm, delay)
-	reply, err := j.Session.Exec(netconf.RawMethod(command))
+// CommitSync commits the configuration on both routing engines, which is the same as
+// "commit synchronize" from the CLI. Devices with a single routing engine just commit normally.
+func (j *Junos) CommitSync() error {
+	if j.dryRun {
+		return j.CommitCheck()
+	}
+
+	reply, err := j.exec(rpcCommitSync)
 	if err != nil {
 		return err
 	}
 
-	if reply.Errors != nil {
-		for _, m := range reply.Errors {
-			return errors.New(m.Message)
-		}
+	if err := joinRPCErrors(reply); err != nil {
+		return err
 	}
 
-	formatted := strings.Replace(reply.Data, "\n", "", -1)
-	err = xml.Unmarshal([]byte(formatted), &errs)
+	return checkCommitResults(reply.Data)
+}
+
+// CommitConfirm commits the configuration, and rolls it back after the delayed minutes
+// unless another Commit() is issued before then. The delay must be between 1 and 65535 minutes.
+func (j *Junos) CommitConfirm(delay int) error {
+	if j.dryRun {
+		return j.CommitCheck()
+	}
+
+	if delay < 1 || delay > 65535 {
+		return errors.New("the commit confirm delay must be between 1 and 65535 minutes")
+	}
+
+	command := fmt.Sprintf(rpcCommitConfirm, delay)
+	reply, err := j.exec(command)
 	if err != nil {
 		return err
 	}
 
-	if errs.Errors != nil {
-		for _, m := range errs.Errors {
-			message := fmt.Sprintf("[%s]\n    %s\nError: %s", strings.Trim(m.Path, "[\r\n]"), strings.Trim(m.Element, "[\r\n]"), strings.Trim(m.Message, "[\r\n]"))
-			return errors.New(message)
-		}
+	if err := joinRPCErrors(reply); err != nil {
+		return err
 	}
 
-	return nil
+	return checkCommitResults(reply.Data)
 }
 
 // Diff compares candidate config to current (rollback 0) or previous rollback
 // this is equivalent to 'show | compare' or 'show | compare rollback X' when
-// in configuration mode
+// in configuration mode. If there are no differences, an empty string is returned.
 // RPC: <get-configuration compare="rollback" rollback="[0-49]" format="text"/>
 // https://goo.gl/wFRMX9 (juniper.net)
 func (j *Junos) Diff(rollback int) (string, error) {
 	var cd cdiffXML
 	command := fmt.Sprintf(rpcGetCandidateCompare, rollback)
-	reply, err := j.Session.Exec(netconf.RawMethod(command))
+	reply, err := j.exec(command)
 	if err != nil {
 		return "", err
 	}
 
-	if reply.Errors != nil {
-		for _, m := range reply.Errors {
-			return "", errors.New(m.Message)
-		}
+	if err := joinRPCErrors(reply); err != nil {
+		return "", err
 	}
 
 	// formatted := strings.Replace(reply.Data, "\n", "", -1)
 	err = xml.Unmarshal([]byte(reply.Data), &cd)
 	if err != nil {
-		return "", err
+		return "", &ParseError{Raw: reply.Data, Err: fmt.Errorf("failed to parse configuration diff - %w", err)}
 	}
 
 	if cd.Error != "" {
@@ -552,71 +1612,179 @@ func (j *Junos) Diff(rollback int) (string, error) {
 		return "", errors.New(errMessage)
 	}
 
+	if strings.TrimSpace(cd.Config) == "" {
+		return "", nil
+	}
+
 	return cd.Config, nil
 }
 
 // GetConfig returns the configuration starting at the given section. If you do not specify anything
-// for section, then the entire configuration will be returned. Format must be "text" or "xml." You
+// for section, then the entire configuration will be returned. Format must be "text", "set", "xml" or "json." You
 // can do sub-sections by separating the section path with a ">" symbol, i.e. "system>login" or "protocols>ospf>area."
+// A single entry of a list can be selected by its name using "=", i.e. "interfaces>interface=ge-0/0/0."
 // The default option is to return the XML.
 func (j *Junos) GetConfig(format string, section ...string) (string, error) {
 	command := fmt.Sprintf("<get-configuration format=\"%s\"><configuration>", format)
 
 	if len(section) > 0 {
-		secs := strings.Split(section[0], ">")
-		nSecs := len(secs) - 1
+		command += configFilter(section[0])
+	}
 
-		if nSecs >= 0 {
-			for i := 0; i < nSecs; i++ {
-				command += fmt.Sprintf("<%s>", secs[i])
-			}
-			command += fmt.Sprintf("<%s/>", secs[nSecs])
+	command += "</configuration></get-configuration>"
 
-			for j := nSecs - 1; j >= 0; j-- {
-				command += fmt.Sprintf("</%s>", secs[j])
-			}
-			command += fmt.Sprint("</configuration></get-configuration>")
-		}
+	reply, err := j.exec(command)
+	if err != nil {
+		return "", err
+	}
+
+	if len(reply.Data) < 50 {
+		return "", errNotConfigured
 	}
 
-	if len(section) <= 0 {
-		command += "</configuration></get-configuration>"
+	if err := joinRPCErrors(reply); err != nil {
+		return "", err
 	}
 
-	reply, err := j.Session.Exec(netconf.RawMethod(command))
+	config, err := configOutput(reply.Data, format)
 	if err != nil {
 		return "", err
 	}
 
-	if len(reply.Data) < 50 {
-		return "", errors.New("the section you provided is not configured on the device")
+	if config == "" {
+		return "", errNotConfigured
+	}
+
+	return config, nil
+}
+
+// GetConfigSection returns the configuration of the given section only, which saves fetching the entire
+// configuration from devices with large configurations. The path and format are the same as for GetConfig,
+// i.e. "system>services" or "interfaces>interface=ge-0/0/0." If the section is not configured on the device,
+// an empty string is returned.
+func (j *Junos) GetConfigSection(path, format string) (string, error) {
+	config, err := j.GetConfig(format, path)
+	if errors.Is(err, errNotConfigured) {
+		return "", nil
 	}
 
-	if reply.Errors != nil {
-		for _, m := range reply.Errors {
-			return "", errors.New(m.Message)
+	return config, err
+}
+
+// configFilter builds the configuration hierarchy for the given section path, i.e. "system>login," that
+// a <get-configuration> request is filtered with. An element of the path can select a single entry of a
+// list by its name, i.e. "interfaces>interface=ge-0/0/0."
+func configFilter(section string) string {
+	var open, close string
+
+	if section == "" {
+		return ""
+	}
+
+	secs := strings.Split(section, ">")
+	for i, sec := range secs {
+		elem, name := sec, ""
+		if idx := strings.Index(sec, "="); idx >= 0 {
+			elem, name = sec[:idx], sec[idx+1:]
+		}
+
+		switch {
+		case name != "":
+			open += fmt.Sprintf("<%s><name>%s</name>", elem, escapeXML(name))
+			close = fmt.Sprintf("</%s>", elem) + close
+		case i == len(secs)-1:
+			open += fmt.Sprintf("<%s/>", elem)
+		default:
+			open += fmt.Sprintf("<%s>", elem)
+			close = fmt.Sprintf("</%s>", elem) + close
+		}
+	}
+
+	return open + close
+}
+
+// configOutput returns the configuration from the data of a <get-configuration> reply in the given format.
+func configOutput(data, format string) (string, error) {
+	switch format {
+	case "text", "set":
+		var output commandXML
+		// formatted := strings.Replace(reply.Data, "\n", "", -1)
+		err := xml.Unmarshal([]byte(data), &output)
+		if err != nil {
+			return "", &ParseError{Raw: data, Err: fmt.Errorf("failed to parse configuration - %w", err)}
+		}
+
+		if len(output.Config) <= 1 {
+			return "", nil
 		}
+
+		return output.Config, nil
+	case "json":
+		return strings.TrimSpace(data), nil
+	}
+
+	return data, nil
+}
+
+// GetRollbackConfig returns the configuration of the given rollback number (0-49) as text. See
+// GetRollbackConfigFormat for other formats.
+func (j *Junos) GetRollbackConfig(number int) (string, error) {
+	return j.GetRollbackConfigFormat(number, "text")
+}
+
+// GetRollbackConfigFormat returns the configuration of the given rollback number (0-49). Format must be
+// "text", "set", "xml" or "json." Using "set" is handy for feeding part of an old configuration back into LoadSet.
+func (j *Junos) GetRollbackConfigFormat(number int, format string) (string, error) {
+	if number < 0 || number > 49 {
+		return "", errors.New("the rollback number must be between 0 and 49")
+	}
+
+	switch format {
+	case "text", "set", "xml", "json":
+	default:
+		return "", errors.New("format must be text, set, xml or json")
+	}
+
+	reply, err := j.exec(fmt.Sprintf(rpcGetRollbackConfig, number, format))
+	if err != nil {
+		return "", err
+	}
+
+	if err := joinRPCErrors(reply); err != nil {
+		return "", err
+	}
+
+	return configOutput(reply.Data, format)
+}
+
+// SaveConfigToFile fetches the entire active (committed) configuration in the given format (see GetConfig) and
+// writes it to the file at path on your local machine, e.g. for nightly backups. Any uncommitted changes in the
+// candidate configuration are not included. Since configurations can contain secrets, the file is created with 0600
+// permissions.
+func (j *Junos) SaveConfigToFile(path, format string) error {
+	reply, err := j.exec(fmt.Sprintf(rpcGetCommittedConfig, format))
+	if err != nil {
+		return err
+	}
+
+	if err := joinRPCErrors(reply); err != nil {
+		return err
 	}
 
-	switch format {
-	case "text":
-		var output commandXML
-		// formatted := strings.Replace(reply.Data, "\n", "", -1)
-		err = xml.Unmarshal([]byte(reply.Data), &output)
-		if err != nil {
-			return "", err
-		}
+	config, err := configOutput(reply.Data, format)
+	if err != nil {
+		return err
+	}
 
-		if len(output.Config) <= 1 {
-			return "", errors.New("the section you provided is not configured on the device")
-		}
+	if config == "" {
+		return errors.New("no configuration was returned by the device")
+	}
 
-		return output.Config, nil
-	case "xml":
-		return reply.Data, nil
+	if err := ioutil.WriteFile(path, []byte(config), 0600); err != nil {
+		return fmt.Errorf("failed to save configuration to %s - %w", path, err)
 	}
 
-	return reply.Data, nil
+	return nil
 }
 
 // Config loads a given configuration file from your local machine,
@@ -688,7 +1856,7 @@ func (j *Junos) Config(path interface{}, format string, commit bool) error {
 		}
 	}
 
-	reply, err := j.Session.Exec(netconf.RawMethod(command))
+	reply, err := j.exec(command)
 	if err != nil {
 		return err
 	}
@@ -700,28 +1868,163 @@ func (j *Junos) Config(path interface{}, format string, commit bool) error {
 		}
 	}
 
-	if reply.Errors != nil {
-		for _, m := range reply.Errors {
-			return errors.New(m.Message)
+	if err := joinRPCErrors(reply); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// LoadSet loads the given "set" and "delete" commands, separated by newlines, into the
+// candidate configuration. The configuration is not committed. If any statement is rejected
+// by the device, it is returned in the error.
+func (j *Junos) LoadSet(config string) error {
+	return j.LoadConfig(config, "set", "merge")
+}
+
+// LoadJSON loads the given JSON configuration document into the candidate configuration, merging
+// it with the existing configuration. The document is checked to be well-formed JSON before it is
+// sent to the device. The configuration is not committed.
+func (j *Junos) LoadJSON(config string) error {
+	return j.LoadConfig(config, "json", "merge")
+}
+
+// LoadFile loads the configuration file at the given path on your local machine into the
+// candidate configuration, merging it with the existing configuration. Format must be "set", "text",
+// "xml" or "json." The configuration is not committed.
+func (j *Junos) LoadFile(path, format string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("unable to read configuration file %s - %w", path, err)
+	}
+
+	return j.LoadConfig(string(data), format, "merge")
+}
+
+// LoadConfig loads the given configuration into the candidate configuration, using the specified
+// load action. Format must be "set", "text", "xml" or "json," and action must be one of the following:
+//
+// merge - combine the configuration with the existing candidate configuration.
+//
+// replace - replace the hierarchies marked with "replace:" tags (text) or the replace="replace"
+// attribute (xml) with the ones given.
+//
+// override - discard the entire candidate configuration and load the one given in its place.
+//
+// Set commands are applied in order, so the "set" format can only be used with merge. The
+// configuration is not committed.
+func (j *Junos) LoadConfig(config, format, action string) error {
+	command, err := loadCommand(config, format, action)
+	if err != nil {
+		return err
+	}
+
+	return j.load(command)
+}
+
+// ApplyConfig locks the candidate configuration, merges the given configuration into it (see LoadConfig for
+// the supported formats), checks it and commits it, and then unlocks the configuration again. If any step
+// fails, the uncommitted changes are discarded and an error naming the failed step is returned. In dry run
+// mode (see SetDryRun), the changes are checked and then always discarded. If the session already holds
+// the lock, the configuration is left locked afterwards.
+func (j *Junos) ApplyConfig(config, format string) error {
+	wasLocked := j.locked
+	if !wasLocked {
+		if err := j.Lock(); err != nil {
+			return fmt.Errorf("failed to lock the configuration - %w", err)
 		}
 	}
 
+	err := j.applyLocked(config, format)
+	if err != nil || j.dryRun {
+		j.DiscardChanges()
+	}
+
+	if wasLocked {
+		return err
+	}
+
+	if uerr := j.Unlock(); uerr != nil && err == nil {
+		return fmt.Errorf("failed to unlock the configuration - %w", uerr)
+	}
+
+	return err
+}
+
+// applyLocked loads, checks and commits the given configuration, which must already be locked.
+func (j *Junos) applyLocked(config, format string) error {
+	if err := j.LoadConfig(config, format, "merge"); err != nil {
+		return fmt.Errorf("failed to load the configuration - %w", err)
+	}
+
+	if err := j.CommitCheck(); err != nil {
+		return fmt.Errorf("the configuration check failed - %w", err)
+	}
+
+	if err := j.Commit(); err != nil {
+		return fmt.Errorf("failed to commit the configuration - %w", err)
+	}
+
 	return nil
 }
 
+// loadCommand builds the <load-configuration> RPC for the given configuration, format and action.
+func loadCommand(config, format, action string) (string, error) {
+	switch action {
+	case "merge", "replace", "override":
+	default:
+		return "", fmt.Errorf("unknown load action %q - must be merge, replace or override", action)
+	}
+
+	switch format {
+	case "set":
+		if action != "merge" {
+			return "", errors.New("set commands can only be loaded using the merge action")
+		}
+
+		return fmt.Sprintf(rpcConfigStringSet, escapeXML(config)), nil
+	case "text":
+		return fmt.Sprintf(rpcLoadConfigText, action, escapeXML(config)), nil
+	case "xml":
+		return fmt.Sprintf(rpcLoadConfigXML, action, config), nil
+	case "json":
+		if !json.Valid([]byte(config)) {
+			return "", errors.New("configuration is not valid JSON")
+		}
+
+		return fmt.Sprintf(rpcLoadConfigJSON, action, escapeXML(config)), nil
+	}
+
+	return "", errors.New("format must be set, text, xml or json")
+}
+
+// load sends the given <load-configuration> RPC, and returns any errors from loading it.
+func (j *Junos) load(command string) error {
+	reply, err := j.exec(command)
+	if err != nil {
+		return err
+	}
+
+	if err := joinRPCErrors(reply); err != nil {
+		return err
+	}
+
+	return checkLoadResults(reply.Data)
+}
+
 // Lock locks the candidate configuration.
 func (j *Junos) Lock() error {
-	reply, err := j.Session.Exec(netconf.RawMethod(rpcLock))
+	reply, err := j.exec(rpcLock)
 	if err != nil {
 		return err
 	}
 
-	if reply.Errors != nil {
-		for _, m := range reply.Errors {
-			return errors.New(m.Message)
-		}
+	if err := joinRPCErrors(reply); err != nil {
+		return err
 	}
 
+	j.locked = true
+
 	if j.CommitTimeout > 0 {
 		time.Sleep(j.CommitTimeout * time.Second)
 	}
@@ -729,28 +2032,70 @@ func (j *Junos) Lock() error {
 	return nil
 }
 
+// IsLocked reports whether this session holds the lock on the candidate configuration, from a
+// successful call to Lock that hasn't been followed by Unlock. The device isn't asked, so locks
+// held by other sessions aren't reported.
+func (j *Junos) IsLocked() bool {
+	return j.locked
+}
+
+// WithLock locks the candidate configuration, runs fn, and then unlocks the configuration again,
+// even if fn returns an error or panics. If fn fails, its error is returned; otherwise any error
+// from unlocking is returned. If the session already holds the lock (see IsLocked), fn is just
+// run, and the configuration is left locked afterwards.
+func (j *Junos) WithLock(fn func() error) (err error) {
+	if j.locked {
+		return fn()
+	}
+
+	if err := j.Lock(); err != nil {
+		return err
+	}
+
+	defer func() {
+		if uerr := j.Unlock(); err == nil {
+			err = uerr
+		}
+	}()
+
+	return fn()
+}
+
 // Rescue will create or delete the rescue configuration given "save" or "delete" for the action.
 func (j *Junos) Rescue(action string) error {
-	var command string
-
 	switch action {
 	case "save":
-		command = fmt.Sprintf(rpcRescueSave)
+		return j.SaveRescueConfig()
 	case "delete":
-		command = fmt.Sprintf(rpcRescueDelete)
-	default:
-		return errors.New("you must specify save or delete for a rescue config action")
+		return j.DeleteRescueConfig()
 	}
 
-	reply, err := j.Session.Exec(netconf.RawMethod(command))
+	return errors.New("you must specify save or delete for a rescue config action")
+}
+
+// DeleteRescueConfig deletes the rescue configuration from the device.
+func (j *Junos) DeleteRescueConfig() error {
+	reply, err := j.exec(rpcRescueDelete)
 	if err != nil {
 		return err
 	}
 
-	if reply.Errors != nil {
-		for _, m := range reply.Errors {
-			return errors.New(m.Message)
-		}
+	if err := joinRPCErrors(reply); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SaveRescueConfig saves the current active configuration as the rescue configuration.
+func (j *Junos) SaveRescueConfig() error {
+	reply, err := j.exec(rpcRescueSave)
+	if err != nil {
+		return err
+	}
+
+	if err := joinRPCErrors(reply); err != nil {
+		return err
 	}
 
 	return nil
@@ -758,44 +2103,58 @@ func (j *Junos) Rescue(action string) error {
 
 // Rollback loads and commits the configuration of a given rollback number or rescue state, by specifying "rescue."
 func (j *Junos) Rollback(option interface{}) error {
-	var command = fmt.Sprintf(rpcRollbackConfig, option)
+	var err error
+
+	switch option := option.(type) {
+	case int:
+		err = j.RollbackConfig(option)
+	case string:
+		if option != "rescue" {
+			return errors.New("you must specify a rollback number or rescue")
+		}
 
-	if option == "rescue" {
-		command = fmt.Sprintf(rpcRescueConfig)
+		err = j.RollbackRescue()
+	default:
+		return errors.New("you must specify a rollback number or rescue")
 	}
 
-	reply, err := j.Session.Exec(netconf.RawMethod(command))
 	if err != nil {
 		return err
 	}
 
-	err = j.Commit()
-	if err != nil {
-		return err
-	}
+	return j.Commit()
+}
 
-	if reply.Errors != nil {
-		for _, m := range reply.Errors {
-			return errors.New(m.Message)
-		}
+// RollbackConfig loads the configuration of the given rollback number (0-49) into the candidate
+// configuration, without committing it. Using 0 resets the candidate configuration to the
+// active configuration.
+func (j *Junos) RollbackConfig(number int) error {
+	if number < 0 || number > 49 {
+		return errors.New("the rollback number must be between 0 and 49")
 	}
 
-	return nil
+	return j.load(fmt.Sprintf(rpcRollbackConfig, number))
+}
+
+// RollbackRescue loads the rescue configuration (see SaveRescueConfig) into the candidate configuration,
+// without committing it.
+func (j *Junos) RollbackRescue() error {
+	return j.load(rpcRescueConfig)
 }
 
 // Unlock unlocks the candidate configuration.
 func (j *Junos) Unlock() error {
-	reply, err := j.Session.Exec(netconf.RawMethod(rpcUnlock))
+	reply, err := j.exec(rpcUnlock)
 	if err != nil {
 		return err
 	}
 
-	if reply.Errors != nil {
-		for _, m := range reply.Errors {
-			return errors.New(m.Message)
-		}
+	if err := joinRPCErrors(reply); err != nil {
+		return err
 	}
 
+	j.locked = false
+
 	if j.CommitTimeout > 0 {
 		time.Sleep(j.CommitTimeout * time.Second)
 	}
@@ -803,35 +2162,242 @@ func (j *Junos) Unlock() error {
 	return nil
 }
 
-// Reboot will reboot the device.
-func (j *Junos) Reboot() error {
-	reply, err := j.Session.Exec(netconf.RawMethod(rpcReboot))
+// LockPrivate opens a private copy of the candidate configuration for this session, which is the
+// same as "configure private" from the CLI. Changes made to it are only merged into the shared
+// candidate configuration when they are committed, so other sessions editing the device don't
+// conflict with ours. Use UnlockPrivate to close it when you are done.
+func (j *Junos) LockPrivate() error {
+	reply, err := j.exec(rpcOpenPrivate)
+	if err != nil {
+		return err
+	}
+
+	if err := joinRPCErrors(reply); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UnlockPrivate closes the private candidate configuration opened with LockPrivate. Any
+// uncommitted changes are discarded.
+func (j *Junos) UnlockPrivate() error {
+	reply, err := j.exec(rpcClosePrivate)
+	if err != nil {
+		return err
+	}
+
+	if err := joinRPCErrors(reply); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DiscardChanges discards any uncommitted changes in the candidate configuration, reverting it
+// to match the active configuration. Any lock held on the configuration is kept.
+func (j *Junos) DiscardChanges() error {
+	reply, err := j.exec(rpcDiscardChanges)
 	if err != nil {
 		return err
 	}
 
-	if reply.Errors != nil {
-		for _, m := range reply.Errors {
-			return errors.New(m.Message)
+	if err := joinRPCErrors(reply); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ClearInterfaceStatistics resets the statistics (counters) of the given interface, or of every interface
+// on the device if iface is empty. If the interface doesn't exist, the error from the device is returned.
+func (j *Junos) ClearInterfaceStatistics(iface string) error {
+	command := rpcClearIntStatsAll
+	if iface != "" {
+		if err := ValidateInterfaceName(iface); err != nil {
+			return err
 		}
+
+		command = fmt.Sprintf(rpcClearIntStats, escapeXML(iface))
+	}
+
+	reply, err := j.exec(command)
+	if err != nil {
+		return err
+	}
+
+	if err := joinRPCErrors(reply); err != nil {
+		return err
 	}
 
 	return nil
 }
 
+// ClearBGPNeighbor resets the BGP session to the given neighbor (peer address), or to every neighbor if
+// neighbor is empty, the same as "clear bgp neighbor." If soft is true, the session is left up, and the routes
+// are re-advertised and re-evaluated against the current policy, i.e. "clear bgp neighbor soft." The acknowledgment
+// from the device is returned, which is empty when the device doesn't send one.
+func (j *Junos) ClearBGPNeighbor(neighbor string, soft bool) (string, error) {
+	var options string
+	if neighbor != "" {
+		options = fmt.Sprintf("<neighbor>%s</neighbor>", escapeXML(neighbor))
+	}
+
+	if soft {
+		options += "<soft/>"
+	}
+
+	reply, err := j.exec(fmt.Sprintf(rpcClearBGPNeighbor, options))
+	if err != nil {
+		return "", err
+	}
+
+	if err := joinRPCErrors(reply); err != nil {
+		return "", err
+	}
+
+	return replyText(reply.Data), nil
+}
+
+// InstallPackage installs the software package at pkgPath on the device, e.g. a Junos image that was
+// copied to /var/tmp using UploadFile. If rebootAfter is true, the device reboots once the package has been
+// installed, closing the session. The output of the install is returned; if the device reports that the install
+// failed, the output is returned along with an error.
+func (j *Junos) InstallPackage(pkgPath string, rebootAfter bool) (string, error) {
+	var results packageResults
+	var reboot string
+
+	if rebootAfter {
+		reboot = "<reboot/>"
+	}
+
+	reply, err := j.exec(fmt.Sprintf(rpcPackageAdd, escapeXML(pkgPath), reboot))
+	if err != nil {
+		return "", err
+	}
+
+	if err := joinRPCErrors(reply); err != nil {
+		return "", err
+	}
+
+	// The reply has no single root element, so wrap it in one to parse the results.
+	if err := xml.Unmarshal([]byte("<results>"+reply.Data+"</results>"), &results); err != nil {
+		return "", &ParseError{Raw: reply.Data, Err: fmt.Errorf("failed to parse package install results - %w", err)}
+	}
+
+	output := strings.TrimSpace(strings.Join(results.Output, "\n"))
+	if results.Result != 0 {
+		return output, fmt.Errorf("failed to install %s - package result %d", pkgPath, results.Result)
+	}
+
+	return output, nil
+}
+
+// Reboot will reboot the device. You can optionally specify the number of minutes to wait before
+// rebooting; otherwise the device reboots immediately. The device closes the session when it reboots,
+// so it can't be used afterwards. To see the acknowledgment from the device, use RebootWithMessage.
+func (j *Junos) Reboot(inMinutes ...int) error {
+	_, err := j.RebootWithMessage(inMinutes...)
+	return err
+}
+
+// RebootWithMessage is the same as Reboot, but returns the acknowledgment from the device, i.e.
+// "Shutdown NOW!" On devices with multiple routing engines, there is a line for each of them.
+func (j *Junos) RebootWithMessage(inMinutes ...int) (string, error) {
+	command := rpcReboot
+	if len(inMinutes) > 0 {
+		if inMinutes[0] < 0 {
+			return "", errors.New("the number of minutes to wait before rebooting can't be negative")
+		}
+
+		command = fmt.Sprintf(rpcRebootIn, inMinutes[0])
+	}
+
+	return j.requestReboot(command)
+}
+
+// Halt will halt (shut down) the device, which then has to be powered back on by hand. The device
+// closes the session when it halts, so it can't be used afterwards. To see the acknowledgment from
+// the device, use HaltWithMessage.
+func (j *Junos) Halt() error {
+	_, err := j.HaltWithMessage()
+	return err
+}
+
+// HaltWithMessage is the same as Halt, but returns the acknowledgment from the device, the same as
+// RebootWithMessage.
+func (j *Junos) HaltWithMessage() (string, error) {
+	return j.requestReboot(rpcHalt)
+}
+
+// requestReboot sends the given reboot or halt RPC, and returns the status from each routing engine.
+func (j *Junos) requestReboot(command string) (string, error) {
+	reply, err := j.exec(command)
+	if err != nil {
+		return "", err
+	}
+
+	if err := joinRPCErrors(reply); err != nil {
+		return "", err
+	}
+
+	formatted := strings.Replace(reply.Data, "\n", "", -1)
+
+	if strings.Contains(reply.Data, "multi-routing-engine-results") {
+		var results multiRebootResults
+		if err := xml.Unmarshal([]byte(formatted), &results); err != nil {
+			return "", &ParseError{Raw: formatted, Err: fmt.Errorf("failed to parse reboot status - %w", err)}
+		}
+
+		var status []string
+		for _, re := range results.RE {
+			status = append(status, fmt.Sprintf("%s: %s", re.Name, strings.TrimSpace(re.Status)))
+		}
+
+		return strings.Join(status, "\n"), nil
+	}
+
+	var results rebootResults
+	if err := xml.Unmarshal([]byte(formatted), &results); err != nil {
+		return "", &ParseError{Raw: formatted, Err: fmt.Errorf("failed to parse reboot status - %w", err)}
+	}
+
+	return strings.TrimSpace(results.Status), nil
+}
+
+// Zeroize erases all of the configuration and data on the device, including the rescue configuration,
+// log files and keys, and resets it to its factory defaults, the same as "request system zeroize." THIS
+// CANNOT BE UNDONE. The acknowledgment from the device is returned, since the device reboots once it has
+// sent it, which closes the session, so it can't be used afterwards. The device will then have to be set
+// up again from its console.
+func (j *Junos) Zeroize() (string, error) {
+	reply, err := j.exec(rpcZeroize)
+	if err != nil {
+		return "", err
+	}
+
+	if err := joinRPCErrors(reply); err != nil {
+		return "", err
+	}
+
+	return replyText(reply.Data), nil
+}
+
 // CommitFull does a full commit on the configuration, which requires all daemons to
 // check and evaluate the new configuration. Useful for when you get an error with
 // a commit or when you've changed the configuration significantly.
 func (j *Junos) CommitFull() error {
-	reply, err := j.Session.Exec(netconf.RawMethod(rpcCommitFull))
+	if j.dryRun {
+		return j.CommitCheck()
+	}
+
+	reply, err := j.exec(rpcCommitFull)
 	if err != nil {
 		return err
 	}
 
-	if reply.Errors != nil {
-		for _, m := range reply.Errors {
-			return errors.New(m.Message)
-		}
+	if err := joinRPCErrors(reply); err != nil {
+		return err
 	}
 
 	return nil
@@ -845,3 +2411,51 @@ func (j *Junos) SetCommitTimeout(delay int) {
 
 	j.CommitTimeout = d
 }
+
+// SecurityFlowSessionCount returns the number of active sessions in the flow session table, the same as "show security
+// flow session summary." On devices with multiple SPU's, the sessions on each of them are added together. On a chassis
+// cluster, the count from the node with the most sessions is returned, since sessions are synchronized between nodes.
+// This is only available on SRX's.
+func (j *Junos) SecurityFlowSessionCount() (int, error) {
+	var nodes []flowSessionSummary
+	reply, err := j.exec(rpcFlowSessionSummary)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := joinRPCErrors(reply); err != nil {
+		return 0, err
+	}
+
+	formatted := strings.Replace(reply.Data, "\n", "", -1)
+
+	if strings.Contains(reply.Data, "multi-routing-engine-results") {
+		var summaries flowSessionSummaries
+		if err := xml.Unmarshal([]byte(formatted), &summaries); err != nil {
+			return 0, &ParseError{Raw: formatted, Err: fmt.Errorf("failed to parse flow session summary - %w", err)}
+		}
+
+		nodes = summaries.Nodes
+	} else {
+		var summary flowSessionSummary
+		if err := xml.Unmarshal([]byte(formatted), &summary); err != nil {
+			return 0, &ParseError{Raw: formatted, Err: fmt.Errorf("failed to parse flow session summary - %w", err)}
+		}
+
+		nodes = append(nodes, summary)
+	}
+
+	count := 0
+	for _, n := range nodes {
+		sessions := 0
+		for _, s := range n.Summaries {
+			sessions += s.ActiveSessions
+		}
+
+		if sessions > count {
+			count = sessions
+		}
+	}
+
+	return count, nil
+}
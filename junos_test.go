@@ -0,0 +1,244 @@
+package junos
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Juniper/go-netconf/netconf"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+func TestCommandRPC(t *testing.T) {
+	tests := []struct {
+		name   string
+		cmd    string
+		format []string
+		want   string
+	}{
+		{
+			name: "plain",
+			cmd:  "show version",
+			want: `<command format="text">show version</command>`,
+		},
+		{
+			name:   "xml format",
+			cmd:    "show version",
+			format: []string{"xml"},
+			want:   `<command format="xml">show version</command>`,
+		},
+		{
+			name:   "json format",
+			cmd:    "show version",
+			format: []string{"json"},
+			want:   `<command format="json">show version</command>`,
+		},
+		{
+			name: "angle brackets",
+			cmd:  "show log messages | match <error>",
+			want: `<command format="text">show log messages | match &lt;error&gt;</command>`,
+		},
+		{
+			name: "ampersand",
+			cmd:  "show interfaces descriptions | match R&D",
+			want: `<command format="text">show interfaces descriptions | match R&amp;D</command>`,
+		},
+		{
+			name: "embedded quotes",
+			cmd:  `show configuration | match "it's down"`,
+			want: `<command format="text">show configuration | match &#34;it&#39;s down&#34;</command>`,
+		},
+		{
+			name: "injected element",
+			cmd:  "show version</command><request-reboot/><command>",
+			want: `<command format="text">show version&lt;/command&gt;&lt;request-reboot/&gt;&lt;command&gt;</command>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := commandRPC(tt.cmd, tt.format...); got != tt.want {
+				t.Errorf("commandRPC(%q) = %q, want %q", tt.cmd, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHostWithPort(t *testing.T) {
+	tests := []struct {
+		host string
+		want string
+	}{
+		{"srx.company.com", "srx.company.com:830"},
+		{"srx.company.com:22", "srx.company.com:22"},
+		{"10.1.1.1", "10.1.1.1:830"},
+		{"2001:db8::1", "[2001:db8::1]:830"},
+		{"[2001:db8::1]", "[2001:db8::1]:830"},
+		{"[2001:db8::1]:2222", "[2001:db8::1]:2222"},
+	}
+
+	for _, tt := range tests {
+		if got := hostWithPort(tt.host, defaultPort); got != tt.want {
+			t.Errorf("hostWithPort(%q) = %q, want %q", tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestKnownHosts(t *testing.T) {
+	newKey := func() ssh.PublicKey {
+		pub, _, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		key, err := ssh.NewPublicKey(pub)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return key
+	}
+
+	key, other := newKey(), newKey()
+
+	dir, err := ioutil.TempDir("", "go-junos")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// An entry as written by ssh when connecting on port 22.
+	path := filepath.Join(dir, "known_hosts")
+	if err := ioutil.WriteFile(path, []byte(knownhosts.Line([]string{"srx.company.com"}, key)+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := genSSHClientConfig("srx.company.com", &AuthMethod{
+		Credentials: []string{"scott", "deathstar"},
+		KnownHosts:  path,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(config.HostKeyAlgorithms) != 1 || config.HostKeyAlgorithms[0] != ssh.KeyAlgoED25519 {
+		t.Errorf("HostKeyAlgorithms = %v, want [%s]", config.HostKeyAlgorithms, ssh.KeyAlgoED25519)
+	}
+
+	remote := &net.TCPAddr{IP: net.ParseIP("10.1.1.1"), Port: 830}
+	if err := config.HostKeyCallback("srx.company.com:830", remote, key); err != nil {
+		t.Errorf("known key was rejected: %v", err)
+	}
+
+	if err := config.HostKeyCallback("srx.company.com:830", remote, other); err == nil {
+		t.Error("mismatched key was accepted")
+	}
+
+	if err := config.HostKeyCallback("other.company.com:830", remote, key); err == nil {
+		t.Error("key for an unknown host was accepted")
+	}
+}
+
+func TestTextOutput(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{
+			name: "escaped characters",
+			data: "<output>\nfilter &lt;term&gt; matched R&amp;D &quot;lab&quot;\n</output>",
+			want: "\nfilter <term> matched R&D \"lab\"\n",
+		},
+		{
+			name: "nested output",
+			data: "\n<configuration-information><configuration-output>set system host-name sw1\nset interfaces &lt;*&gt; mtu 9000\n</configuration-output></configuration-information>\n",
+			want: "set system host-name sw1\nset interfaces <*> mtu 9000\n",
+		},
+		{
+			name: "empty",
+			data: "<output/>",
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := textOutput(tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != tt.want {
+				t.Errorf("textOutput() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// replyTransport is a netconf.Transport that answers every RPC with the same reply.
+type replyTransport struct {
+	reply string
+}
+
+func (t *replyTransport) Send([]byte) error                     { return nil }
+func (t *replyTransport) Receive() ([]byte, error)              { return []byte(t.reply), nil }
+func (t *replyTransport) Close() error                          { return nil }
+func (t *replyTransport) SendHello(*netconf.HelloMessage) error { return nil }
+func (t *replyTransport) ReceiveHello() (*netconf.HelloMessage, error) {
+	return &netconf.HelloMessage{}, nil
+}
+
+func TestRPCErrors(t *testing.T) {
+	rpcError := func(severity, message string) string {
+		return "<rpc-error><error-severity>" + severity + "</error-severity><error-message>\n" + message + "\n</error-message></rpc-error>"
+	}
+
+	tests := []struct {
+		name    string
+		errors  string
+		wantErr string
+	}{
+		{
+			name:    "two errors and a warning",
+			errors:  rpcError("error", "first [a]") + rpcError("warning", "just a warning") + rpcError("error", "second [b]"),
+			wantErr: "first [a]\nsecond [b]",
+		},
+		{
+			name:   "only a warning",
+			errors: rpcError("warning", "just a warning"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j := &Junos{Session: &netconf.Session{Transport: &replyTransport{"<rpc-reply>" + tt.errors + "</rpc-reply>"}}}
+
+			err := j.Lock()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Lock() error = %v, want nil", err)
+				}
+
+				if !j.IsLocked() {
+					t.Error("IsLocked() = false after a successful Lock()")
+				}
+
+				return
+			}
+
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("Lock() error = %v, want %q", err, tt.wantErr)
+			}
+
+			if j.IsLocked() {
+				t.Error("IsLocked() = true after a failed Lock()")
+			}
+		})
+	}
+}
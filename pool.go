@@ -0,0 +1,87 @@
+package junos
+
+import "sync"
+
+// SessionPool keeps open sessions to devices so that they can be reused, instead of connecting to the
+// same device over and over again. Sessions are taken from the pool with Get, and handed back with Put
+// once you are done with them. A session is only ever given to one caller at a time, so it is safe to
+// use a SessionPool from multiple goroutines. Sessions are matched by both the host and the username
+// they were created with.
+type SessionPool struct {
+	mu       sync.Mutex
+	maxSize  int
+	sessions map[string]*Junos
+}
+
+// NewSessionPool creates a SessionPool that holds on to at most maxSize idle sessions.
+func NewSessionPool(maxSize int) *SessionPool {
+	return &SessionPool{
+		maxSize:  maxSize,
+		sessions: make(map[string]*Junos),
+	}
+}
+
+// Get returns an idle session to the given host from the pool, if there is one that is still alive (see Ping).
+// Otherwise, a new session is established using NewSession. Dead sessions are closed and discarded.
+func (p *SessionPool) Get(host string, auth *AuthMethod) (*Junos, error) {
+	user := auth.Username
+	if len(auth.Credentials) > 0 {
+		user = auth.Credentials[0]
+	}
+
+	key := poolKey(host, user)
+
+	p.mu.Lock()
+	j, ok := p.sessions[key]
+	delete(p.sessions, key)
+	p.mu.Unlock()
+
+	if ok {
+		if err := j.Ping(); err == nil {
+			return j, nil
+		}
+
+		j.Close()
+	}
+
+	return NewSession(host, auth)
+}
+
+// Put hands the given session back to the pool, so that it can be reused by a later call to Get. If the pool
+// is full, or already has an idle session to the same host and user, the session is closed instead. Sessions
+// created with NewSessionFromNetconf are always closed, since they can't be matched to a host.
+func (p *SessionPool) Put(j *Junos) {
+	if j == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := poolKey(j.host, j.user)
+	if _, ok := p.sessions[key]; ok || j.host == "" || len(p.sessions) >= p.maxSize {
+		j.Close()
+		return
+	}
+
+	p.sessions[key] = j
+}
+
+// Close closes every idle session in the pool. Sessions that are currently in use are not affected, and
+// are closed when they're handed back with Put.
+func (p *SessionPool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for key, j := range p.sessions {
+		j.Close()
+		delete(p.sessions, key)
+	}
+
+	p.maxSize = 0
+}
+
+// poolKey returns the key that sessions to the given host, for the given user, are stored under.
+func poolKey(host, user string) string {
+	return user + "@" + host
+}
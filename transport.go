@@ -0,0 +1,140 @@
+package junos
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/Juniper/go-netconf/netconf"
+	"golang.org/x/crypto/ssh"
+)
+
+// msgSeparator marks the end of each NETCONF message when using end-of-message framing.
+const msgSeparator = "]]>]]>"
+
+// shellCommand starts NETCONF from the Junos CLI, framing each message with the message separator.
+const shellCommand = "xml-mode netconf need-trailer"
+
+// framedTransport is a netconf.Transport that sends and receives NETCONF messages over any stream, such as
+// a TLS connection, using end-of-message framing.
+type framedTransport struct {
+	conn io.ReadWriteCloser
+	r    *bufio.Reader
+}
+
+func newFramedTransport(conn io.ReadWriteCloser) *framedTransport {
+	return &framedTransport{conn: conn, r: bufio.NewReader(conn)}
+}
+
+// Send writes the given message, followed by the message separator.
+func (t *framedTransport) Send(data []byte) error {
+	msg := make([]byte, 0, len(data)+len(msgSeparator)+1)
+	msg = append(msg, data...)
+	msg = append(msg, msgSeparator+"\n"...)
+
+	_, err := t.conn.Write(msg)
+	return err
+}
+
+// Receive reads the next message, up to the message separator.
+func (t *framedTransport) Receive() ([]byte, error) {
+	var msg []byte
+
+	for {
+		chunk, err := t.r.ReadBytes('>')
+		msg = append(msg, chunk...)
+
+		if bytes.HasSuffix(msg, []byte(msgSeparator)) {
+			return msg[:len(msg)-len(msgSeparator)], nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Close closes the underlying connection.
+func (t *framedTransport) Close() error {
+	return t.conn.Close()
+}
+
+// SendHello sends our hello message, with our capabilities.
+func (t *framedTransport) SendHello(hello *netconf.HelloMessage) error {
+	val, err := xml.Marshal(hello)
+	if err != nil {
+		return err
+	}
+
+	return t.Send(append([]byte(xml.Header), val...))
+}
+
+// ReceiveHello reads the hello message from the device.
+func (t *framedTransport) ReceiveHello() (*netconf.HelloMessage, error) {
+	hello := new(netconf.HelloMessage)
+
+	val, err := t.Receive()
+	if err != nil {
+		return hello, err
+	}
+
+	err = xml.Unmarshal(val, hello)
+	return hello, err
+}
+
+// shellConn is the CLI of the device, running NETCONF, over an SSH session.
+type shellConn struct {
+	io.Reader
+	io.WriteCloser
+	client  *ssh.Client
+	session *ssh.Session
+}
+
+// Close closes the SSH session and the connection to the device.
+func (c *shellConn) Close() error {
+	c.session.Close()
+	return c.client.Close()
+}
+
+// dialShell connects to the device at target (host:port) and starts NETCONF from the CLI, instead of
+// requesting the netconf subsystem.
+func dialShell(target string, clientConfig *ssh.ClientConfig) (*netconf.Session, error) {
+	client, err := ssh.Dial("tcp", target, clientConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := client.NewSession()
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	conn := &shellConn{client: client, session: session}
+
+	if conn.WriteCloser, err = session.StdinPipe(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if conn.Reader, err = session.StdoutPipe(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if err := session.Start(shellCommand); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to start NETCONF from the CLI - %w", err)
+	}
+
+	s := netconf.NewSession(newFramedTransport(conn))
+	if len(s.ServerCapabilities) == 0 {
+		conn.Close()
+		return nil, errors.New("the device did not start a NETCONF session from the CLI")
+	}
+
+	return s, nil
+}
@@ -4,7 +4,10 @@ import (
 	"encoding/xml"
 	"errors"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Juniper/go-netconf/netconf"
 )
@@ -15,11 +18,13 @@ type ArpTable struct {
 	Entries []ArpEntry `xml:"arp-table-entry"`
 }
 
-// ArpEntry holds each individual ARP entry.
+// ArpEntry holds each individual ARP entry. The ARP table is gathered without resolving names
+// (no-resolve), so Hostname is normally the same as the IP address.
 type ArpEntry struct {
 	MACAddress string `xml:"mac-address"`
 	IPAddress  string `xml:"ip-address"`
 	Interface  string `xml:"interface-name"`
+	Hostname   string `xml:"hostname"`
 }
 
 // RoutingTable contains every routing table on the device.
@@ -107,6 +112,32 @@ type AddressFamily struct {
 	MTU                string `xml:"mtu"`
 }
 
+// Interface contains the status of a physical interface, as shown by "show interfaces terse."
+type Interface struct {
+	Name        string          `xml:"name"`
+	AdminStatus string          `xml:"admin-status"`
+	OperStatus  string          `xml:"oper-status"`
+	Units       []InterfaceUnit `xml:"logical-interface"`
+}
+
+// InterfaceUnit contains the status and addresses of each logical interface (unit) tied to a physical interface.
+type InterfaceUnit struct {
+	Name        string            `xml:"name"`
+	AdminStatus string            `xml:"admin-status"`
+	OperStatus  string            `xml:"oper-status"`
+	Families    []InterfaceFamily `xml:"address-family"`
+}
+
+// InterfaceFamily contains every address configured for an address family (inet, inet6, etc.) on a logical interface.
+type InterfaceFamily struct {
+	Name      string   `xml:"address-family-name"`
+	Addresses []string `xml:"interface-address>ifa-local"`
+}
+
+type terseInterfaces struct {
+	Entries []Interface `xml:"physical-interface"`
+}
+
 // Vlans contains all of the VLAN information on the device.
 type Vlans struct {
 	Entries []Vlan `xml:"l2ng-l2ald-vlan-instance-group"`
@@ -119,11 +150,25 @@ type Vlan struct {
 	MemberInterfaces []string `xml:"l2ng-l2rtb-vlan-member>l2ng-l2rtb-vlan-member-interface"`
 }
 
+// legacyVlans contains the VLAN information on switches that don't run ELS (Enhanced Layer 2 Software),
+// such as older EX's.
+type legacyVlans struct {
+	Entries []struct {
+		Name             string   `xml:"vlan-name"`
+		Tag              int      `xml:"vlan-tag"`
+		MemberInterfaces []string `xml:"vlan-members>vlan-member>vlan-member-interface"`
+	} `xml:"vlan"`
+}
+
+// LLDPNeighbors contains a list of LLDP neighbors.
 type LLDPNeighbors struct {
 	Entries []LLDPNeighbor `xml:"lldp-neighbor-information"`
 }
 
+// LLDPNeighbor contains information about each LLDP neighbor. Depending on the platform and version, the local
+// interface is reported in either LocalInterface or LocalPortId.
 type LLDPNeighbor struct {
+	LocalInterface           string `xml:"lldp-local-interface"`
 	LocalPortId              string `xml:"lldp-local-port-id"`
 	LocalParentInterfaceName string `xml:"lldp-local-parent-interface-name"`
 	RemoteChassisIdSubtype   string `xml:"lldp-remote-chassis-id-subtype"`
@@ -149,7 +194,7 @@ type L2MACEntry struct {
 
 // MACEntry contains information about each individual MAC address. Flags are: S - static MAC, D - dynamic MAC,
 // L - locally learned, P - persistent static, SE - statistics enabled, NM - non configured MAC, R - remote PE MAC,
-// O - ovsdb MAC.
+// O - ovsdb MAC. On switches that don't run ELS, Flags holds the type of the entry instead, i.e. "Learn" or "Static."
 type MACEntry struct {
 	VlanName         string `xml:"l2ng-l2-mac-vlan-name"`
 	MACAddress       string `xml:"l2ng-l2-mac-address"`
@@ -158,6 +203,22 @@ type MACEntry struct {
 	LogicalInterface string `xml:"l2ng-l2-mac-logical-interface"`
 }
 
+// legacyEthernetSwitchingTable contains the ethernet-switching table on switches that don't run ELS
+// (Enhanced Layer 2 Software), such as older EX's.
+type legacyEthernetSwitchingTable struct {
+	Tables []struct {
+		GlobalMACCount  int `xml:"mac-table-count"`
+		LearnedMACCount int `xml:"mac-table-learned"`
+		Entries         []struct {
+			VlanName   string   `xml:"mac-vlan"`
+			MACAddress string   `xml:"mac-address"`
+			Type       string   `xml:"mac-type"`
+			Age        string   `xml:"mac-age"`
+			Interfaces []string `xml:"mac-interfaces-list>mac-interfaces"`
+		} `xml:"mac-table-entry"`
+	} `xml:"ethernet-switching-table"`
+}
+
 // HardwareInventory contains all the hardware information about the device.
 type HardwareInventory struct {
 	Chassis []Chassis `xml:"chassis"`
@@ -191,6 +252,11 @@ type FileSystem struct {
 	MountedOn       string `xml:"mounted-on"`
 }
 
+// PercentUsed returns UsedPercent as a number, so that it can be compared against a threshold.
+func (f FileSystem) PercentUsed() (int, error) {
+	return strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(f.UsedPercent), "%"))
+}
+
 // Chassis contains all of the hardware information for each chassis, such as a clustered pair of SRX's or a
 // virtual-chassis configuration.
 type Chassis struct {
@@ -244,6 +310,16 @@ type SubSubSubModule struct {
 	Description  string `xml:"description"`
 }
 
+// Component contains the information for a single piece of hardware, from a chassis all the way
+// down to an individual SFP. See HardwareInventory.
+type Component struct {
+	Name         string
+	Version      string
+	PartNumber   string
+	SerialNumber string
+	Description  string
+}
+
 // VirtualChassis contains information regarding the virtual-chassis setup for the device.
 type VirtualChassis struct {
 	PreProvisionedVCID   string     `xml:"preprovisioned-virtual-chassis-information>virtual-chassis-id"`
@@ -404,17 +480,293 @@ type Rule struct {
 	} `xml:"policy-action>policy-tcp-options"`
 }
 
+// SystemAlarms contains all of the active system alarms on the device.
+type SystemAlarms struct {
+	Count   int     `xml:"alarm-summary>active-alarm-count"`
+	Entries []Alarm `xml:"alarm-detail"`
+}
+
+type srxSystemAlarms struct {
+	Entries []Alarm `xml:"multi-routing-engine-item>alarm-information>alarm-detail"`
+}
+
+// Alarm contains information about each individual alarm.
+type Alarm struct {
+	Time             string `xml:"alarm-time"`
+	Class            string `xml:"alarm-class"`
+	Description      string `xml:"alarm-description"`
+	ShortDescription string `xml:"alarm-short-description"`
+	Type             string `xml:"alarm-type"`
+}
+
+// Environment contains the status of all of the environmental sensors on the device, such as temperatures,
+// fans and power supplies.
+type Environment struct {
+	Items []EnvItem `xml:"environment-item"`
+}
+
+type multiEnvironment struct {
+	Items []EnvItem `xml:"multi-routing-engine-item>environment-information>environment-item"`
+}
+
+// EnvItem contains the information for each individual sensor. Class is the type of sensor, i.e. "Temp", "Fans"
+// or "Power", and Measurement is the reading as displayed on the CLI, such as "30 degrees C / 86 degrees F."
+type EnvItem struct {
+	Name        string `xml:"name"`
+	Class       string `xml:"class"`
+	Status      string `xml:"status"`
+	Measurement string `xml:"temperature"`
+	Comment     string `xml:"comment"`
+}
+
+// OSPFNeighbors contains a list of OSPF neighbors.
+type OSPFNeighbors struct {
+	Entries []OSPFNeighbor `xml:"ospf-neighbor"`
+}
+
+// OSPFNeighbor contains information about each OSPF neighbor.
+type OSPFNeighbor struct {
+	Address   string `xml:"neighbor-address"`
+	Interface string `xml:"interface-name"`
+	State     string `xml:"ospf-neighbor-state"`
+	ID        string `xml:"neighbor-id"`
+	Priority  int    `xml:"neighbor-priority"`
+	DeadTime  int    `xml:"activity-timer"`
+}
+
+// RouteEngines contains the status of each routing engine on the device.
+type RouteEngines struct {
+	Entries []RouteEngine `xml:"route-engine"`
+}
+
+type multiRouteEngines struct {
+	Entries []RouteEngine `xml:"multi-routing-engine-item>route-engine-information>route-engine"`
+}
+
+// RouteEngine contains the status of each individual routing engine, such as its CPU and memory usage.
+// CPUIdle and MemoryUtilization are percentages.
+type RouteEngine struct {
+	Slot              int    `xml:"slot"`
+	MastershipState   string `xml:"mastership-state"`
+	Status            string `xml:"status"`
+	CPUIdle           int    `xml:"cpu-idle"`
+	MemoryUtilization int    `xml:"memory-buffer-utilization"`
+	Temperature       string `xml:"temperature"`
+	Model             string `xml:"model"`
+	UpTime            string `xml:"up-time"`
+	LastRebootReason  string `xml:"last-reboot-reason"`
+}
+
+// FirewallFilters contains the counters of every firewall filter on the device.
+type FirewallFilters struct {
+	Entries []FirewallFilter `xml:"filter-information"`
+}
+
+// FirewallFilter contains the counters for each individual firewall filter.
+type FirewallFilter struct {
+	Name     string            `xml:"filter-name"`
+	Counters []FirewallCounter `xml:"counter"`
+}
+
+// FirewallCounter contains the packet and byte counts of each firewall filter counter. FilterName is the
+// name of the filter the counter belongs to.
+type FirewallCounter struct {
+	FilterName  string `xml:"-"`
+	CounterName string `xml:"counter-name"`
+	PacketCount int64  `xml:"packet-count"`
+	ByteCount   int64  `xml:"byte-count"`
+}
+
+// FPCs contains the status and resource utilization of each FPC on the device.
+type FPCs struct {
+	Entries []FPC `xml:"fpc"`
+}
+
+type multiFPCs struct {
+	Entries []FPC `xml:"multi-routing-engine-item>fpc-information>fpc"`
+}
+
+// FPC contains the status of each individual FPC. Temperature is in degrees celsius, and CPUTotal,
+// MemoryHeapUtilization and MemoryBufferUtilization are percentages.
+type FPC struct {
+	Slot                    int    `xml:"slot"`
+	State                   string `xml:"state"`
+	Temperature             int    `xml:"temperature"`
+	CPUTotal                int    `xml:"cpu-total"`
+	CPUInterrupt            int    `xml:"cpu-interrupt"`
+	MemoryDRAMSize          int    `xml:"memory-dram-size"`
+	MemoryHeapUtilization   int    `xml:"memory-heap-utilization"`
+	MemoryBufferUtilization int    `xml:"memory-buffer-utilization"`
+}
+
+// PoEInterfaces contains the PoE (Power over Ethernet) status of every interface on the switch.
+type PoEInterfaces struct {
+	Entries []PoEInterface
+}
+
+// PoEInterface contains the PoE status of each individual interface. PowerLimit and PowerConsumption are in watts.
+type PoEInterface struct {
+	Interface        string
+	AdminStatus      string
+	OperStatus       string
+	PowerLimit       float64
+	PowerConsumption float64
+	Priority         string
+	Class            string
+}
+
+type poeInformation struct {
+	Entries []struct {
+		Interface   string `xml:"interface-name"`
+		AdminStatus string `xml:"interface-enabled"`
+		OperStatus  string `xml:"interface-status"`
+		PowerLimit  string `xml:"interface-power-limit"`
+		Power       string `xml:"interface-power"`
+		Priority    string `xml:"interface-priority"`
+		Class       string `xml:"interface-class"`
+	} `xml:"interface-information"`
+}
+
+// parseNumber returns the number at the start of s, ignoring any units after it, i.e. "15.4W" or "-2.50 dBm."
+// If there is no number, such as for "- Inf" or "N/A", 0 is returned.
+func parseNumber(s string) float64 {
+	s = strings.TrimSpace(s)
+	end := strings.IndexFunc(s, func(r rune) bool {
+		return !strings.ContainsRune("+-.0123456789", r)
+	})
+
+	if end >= 0 {
+		s = s[:end]
+	}
+
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+
+	return n
+}
+
+// OpticsDiagnostics contains the optics (DOM) diagnostics of every optical interface on the device.
+type OpticsDiagnostics struct {
+	Entries []OpticLane
+}
+
+// OpticLane contains the diagnostics of each lane of an optic. Optics with a single lane, such as SFP's, have
+// one entry with a Lane of 0. Power readings and thresholds are in dBm, and temperatures are in degrees celsius.
+// The temperature and its thresholds are for the whole module, so they are the same for every lane.
+type OpticLane struct {
+	Interface            string
+	Lane                 int
+	RxPower              float64
+	TxPower              float64
+	Temperature          float64
+	RxPowerHighAlarm     float64
+	RxPowerLowAlarm      float64
+	TxPowerHighAlarm     float64
+	TxPowerLowAlarm      float64
+	TemperatureHighAlarm float64
+	TemperatureLowAlarm  float64
+}
+
+type opticsInformation struct {
+	Interfaces []struct {
+		Name        string `xml:"name"`
+		Diagnostics struct {
+			TxPower              string `xml:"laser-output-power-dbm"`
+			RxPower              string `xml:"rx-signal-avg-optical-power-dbm"`
+			Temperature          string `xml:"module-temperature"`
+			RxPowerHighAlarm     string `xml:"laser-rx-power-high-alarm-threshold-dbm"`
+			RxPowerLowAlarm      string `xml:"laser-rx-power-low-alarm-threshold-dbm"`
+			TxPowerHighAlarm     string `xml:"laser-tx-power-high-alarm-threshold-dbm"`
+			TxPowerLowAlarm      string `xml:"laser-tx-power-low-alarm-threshold-dbm"`
+			TemperatureHighAlarm string `xml:"module-temperature-high-alarm-threshold"`
+			TemperatureLowAlarm  string `xml:"module-temperature-low-alarm-threshold"`
+			Lanes                []struct {
+				Index   int    `xml:"lane-index"`
+				TxPower string `xml:"laser-output-power-dbm"`
+				RxPower string `xml:"laser-rx-optical-power-dbm"`
+			} `xml:"optics-diagnostics-lane-values"`
+		} `xml:"optics-diagnostics"`
+	} `xml:"physical-interface"`
+}
+
+// RouteSummary contains a summary of every routing table on the device.
+type RouteSummary struct {
+	ASNumber string              `xml:"as-number"`
+	RouterID string              `xml:"router-id"`
+	Tables   []RouteTableSummary `xml:"route-table"`
+}
+
+// RouteTableSummary contains the number of routes in each routing table, along with how many came from each protocol.
+type RouteTableSummary struct {
+	TableName      string                 `xml:"table-name"`
+	Destinations   int                    `xml:"destination-count"`
+	TotalRoutes    int                    `xml:"total-route-count"`
+	ActiveRoutes   int                    `xml:"active-route-count"`
+	HolddownRoutes int                    `xml:"holddown-route-count"`
+	HiddenRoutes   int                    `xml:"hidden-route-count"`
+	Protocols      []ProtocolRouteSummary `xml:"protocols"`
+}
+
+// ProtocolRouteSummary contains the number of routes learned from each protocol, i.e. "BGP" or "Static."
+type ProtocolRouteSummary struct {
+	Protocol     string `xml:"protocol-name"`
+	TotalRoutes  int    `xml:"protocol-route-count"`
+	ActiveRoutes int    `xml:"active-route-count"`
+}
+
+// DHCPBindings contains every lease handed out by the DHCP server on the device.
+type DHCPBindings struct {
+	Entries []DHCPBinding
+}
+
+// DHCPBinding contains information about each individual DHCP lease. LeaseExpires is the zero time if the
+// lease never expires. LeaseExpiresText is the expiry exactly as the device reported it.
+type DHCPBinding struct {
+	IPAddress        string
+	MACAddress       string
+	State            string
+	LeaseExpires     time.Time
+	LeaseExpiresText string
+	Interface        string
+}
+
+type dhcpBindingInformation struct {
+	Entries []struct {
+		IPAddress    string `xml:"allocated-address"`
+		MACAddress   string `xml:"mac-address"`
+		State        string `xml:"binding-state"`
+		Type         string `xml:"binding-type"`
+		Interface    string `xml:"interface-name"`
+		LeaseExpires struct {
+			Text    string `xml:",chardata"`
+			Seconds int64  `xml:"seconds,attr"`
+		} `xml:"lease-expires"`
+	} `xml:"dhcp-binding"`
+}
+
 // Views contains the information for the specific views. Note that some views aren't available for specific
 // hardware platforms, such as the "VirtualChassis" view on an SRX.
 type Views struct {
+	Alarms         SystemAlarms
 	Arp            ArpTable
 	BGP            BGPTable
+	DHCPBindings   DHCPBindings
+	Environment    Environment
 	EthernetSwitch EthernetSwitchingTable
+	FPC            FPCs
+	Firewall       FirewallFilters
 	FirewallPolicy FirewallPolicy
 	Interface      Interfaces
 	Inventory      HardwareInventory
 	LLDPNeighbors  LLDPNeighbors
+	OSPF           OSPFNeighbors
+	Optics         OpticsDiagnostics
+	PoE            PoEInterfaces
 	Route          RoutingTable
+	RouteEngines   RouteEngines
+	RouteSummary   RouteSummary
 	SourceNat      SourceNats
 	StaticNat      StaticNats
 	Storage        Storage
@@ -437,6 +789,16 @@ var (
 		"sourcenat":      "<get-source-nat-rule-sets-information><all/></get-source-nat-rule-sets-information>",
 		"storage":        "<get-system-storage/>",
 		"firewallpolicy": "<get-firewall-policies/>",
+		"alarms":         "<get-system-alarm-information/>",
+		"environment":    "<get-environment-information/>",
+		"ospf":           "<get-ospf-neighbor-information/>",
+		"routingengine":  "<get-route-engine-information/>",
+		"firewall":       "<get-firewall-information/>",
+		"fpc":            "<get-fpc-information/>",
+		"poe":            "<get-poe-interface-information/>",
+		"optics":         "<get-interface-optics-diagnostics-information/>",
+		"routesummary":   "<get-route-summary-information/>",
+		"dhcpbinding":    "<get-dhcp-server-binding-information/>",
 	}
 )
 
@@ -450,6 +812,10 @@ func validatePlatform(j *Junos, v string) error {
 		if strings.Contains(j.Platform[0].Model, "SRX") || strings.Contains(j.Platform[0].Model, "MX") {
 			return errors.New("virtual-chassis information is not available on this platform")
 		}
+	case "poe":
+		if strings.Contains(j.Platform[0].Model, "MX") {
+			return errors.New("PoE information is not available on this platform")
+		}
 	}
 
 	return nil
@@ -458,12 +824,21 @@ func validatePlatform(j *Junos, v string) error {
 // View gathers information on the device given the "view" specified. These views can be interrated/looped over to view the
 // data (i.e. ARP table entries, interface details/statistics, routing tables, etc.). Supported views are:
 //
-// arp, route, bgp, interface, vlan, ethernetswitch, inventory, virtualchassis, staticnat, sourcenat, storage, fireawllpolicy
+// arp, route, bgp, interface, vlan, ethernetswitch, inventory, virtualchassis, staticnat, sourcenat, storage, fireawllpolicy,
+// alarms, environment, ospf, routingengine, firewall, fpc, poe, optics, routesummary, dhcpbinding
 //
 // By default, the interface view will return all interfaces on the device. If you wish to only see a particular physical interface,
 // and all logical interfaces underneath it, you can use the option parameter to specify the name of the interface, e.g.:
 //
 // View("interface", "ge-0/0/0")
+//
+// The route view works the same way, where you can specify the name of a routing table to only view
+// the routes within it, e.g.:
+//
+// View("route", "inet.0")
+//
+// As does the firewall view, where you can specify the name of a firewall filter to only view its counters, and
+// the optics view, where you can specify the name of an interface to only view the diagnostics of its optic.
 func (j *Junos) View(view string, option ...string) (*Views, error) {
 	var results Views
 	var reply *netconf.RPCReply
@@ -477,26 +852,54 @@ func (j *Junos) View(view string, option ...string) (*Views, error) {
 		}
 	}
 
-	if view == "interface" && len(option) > 0 {
+	if view == "interface" && len(option) > 0 && option[0] != "" {
+		if err := ValidateInterfaceName(option[0]); err != nil {
+			return nil, err
+		}
+
 		rpcIntName := fmt.Sprintf("<get-interface-information><interface-name>%s</interface-name></get-interface-information>", option[0])
-		reply, err = j.Session.Exec(netconf.RawMethod(rpcIntName))
+		reply, err = j.exec(rpcIntName)
+		if err != nil {
+			return nil, err
+		}
+	} else if view == "route" && len(option) > 0 && option[0] != "" {
+		rpcRouteTable := fmt.Sprintf("<get-route-information><table>%s</table></get-route-information>", escapeXML(option[0]))
+		reply, err = j.exec(rpcRouteTable)
+		if err != nil {
+			return nil, err
+		}
+	} else if view == "optics" && len(option) > 0 && option[0] != "" {
+		if err := ValidateInterfaceName(option[0]); err != nil {
+			return nil, err
+		}
+
+		rpcOptics := fmt.Sprintf("<get-interface-optics-diagnostics-information><interface-name>%s</interface-name></get-interface-optics-diagnostics-information>", escapeXML(option[0]))
+		reply, err = j.exec(rpcOptics)
+		if err != nil {
+			return nil, err
+		}
+	} else if view == "firewall" && len(option) > 0 && option[0] != "" {
+		rpcFilter := fmt.Sprintf("<get-firewall-filter-information><filtername>%s</filtername></get-firewall-filter-information>", escapeXML(option[0]))
+		reply, err = j.exec(rpcFilter)
 		if err != nil {
 			return nil, err
 		}
 	} else {
-		reply, err = j.Session.Exec(netconf.RawMethod(viewCategories[view]))
+		reply, err = j.exec(viewCategories[view])
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	if reply.Errors != nil {
-		for _, m := range reply.Errors {
-			return nil, errors.New(m.Message)
-		}
+	if err := joinRPCErrors(reply); err != nil {
+		return nil, err
 	}
 
 	if reply.Data == "" {
+		if j.allowEmpty {
+			return &results, nil
+		}
+
 		return nil, errors.New("no output available - please check the syntax of your command")
 	}
 
@@ -506,7 +909,7 @@ func (j *Junos) View(view string, option ...string) (*Views, error) {
 		formatted := strings.Replace(reply.Data, "\n", "", -1)
 
 		if err := xml.Unmarshal([]byte(formatted), &arpTable); err != nil {
-			return nil, err
+			return nil, &ParseError{Raw: formatted, Err: err}
 		}
 
 		results.Arp = arpTable
@@ -515,7 +918,7 @@ func (j *Junos) View(view string, option ...string) (*Views, error) {
 		formatted := strings.Replace(reply.Data, "\n", "", -1)
 
 		if err := xml.Unmarshal([]byte(formatted), &routingTable); err != nil {
-			return nil, err
+			return nil, &ParseError{Raw: formatted, Err: err}
 		}
 
 		results.Route = routingTable
@@ -524,7 +927,7 @@ func (j *Junos) View(view string, option ...string) (*Views, error) {
 		formatted := strings.Replace(reply.Data, "\n", "", -1)
 
 		if err := xml.Unmarshal([]byte(formatted), &ints); err != nil {
-			return nil, err
+			return nil, &ParseError{Raw: formatted, Err: err}
 		}
 
 		results.Interface = ints
@@ -532,8 +935,20 @@ func (j *Junos) View(view string, option ...string) (*Views, error) {
 		var vlan Vlans
 		formatted := strings.Replace(reply.Data, "\n", "", -1)
 
-		if err := xml.Unmarshal([]byte(formatted), &vlan); err != nil {
-			return nil, err
+		if strings.Contains(reply.Data, "<vlan-information") {
+			var legacy legacyVlans
+
+			if err := xml.Unmarshal([]byte(formatted), &legacy); err != nil {
+				return nil, &ParseError{Raw: formatted, Err: err}
+			}
+
+			for _, v := range legacy.Entries {
+				vlan.Entries = append(vlan.Entries, Vlan{Name: v.Name, Tag: v.Tag, MemberInterfaces: v.MemberInterfaces})
+			}
+		} else {
+			if err := xml.Unmarshal([]byte(formatted), &vlan); err != nil {
+				return nil, &ParseError{Raw: formatted, Err: err}
+			}
 		}
 
 		results.Vlan = vlan
@@ -543,7 +958,7 @@ func (j *Junos) View(view string, option ...string) (*Views, error) {
 		formatted := strings.Replace(reply.Data, "\n", "", -1)
 
 		if err := xml.Unmarshal([]byte(formatted), &lldpNeighbors); err != nil {
-			return nil, err
+			return nil, &ParseError{Raw: formatted, Err: err}
 		}
 
 		results.LLDPNeighbors = lldpNeighbors
@@ -551,8 +966,32 @@ func (j *Junos) View(view string, option ...string) (*Views, error) {
 		var ethtable EthernetSwitchingTable
 		formatted := strings.Replace(reply.Data, "\n", "", -1)
 
-		if err := xml.Unmarshal([]byte(formatted), &ethtable); err != nil {
-			return nil, err
+		if strings.Contains(reply.Data, "<ethernet-switching-table-information") {
+			var legacy legacyEthernetSwitchingTable
+
+			if err := xml.Unmarshal([]byte(formatted), &legacy); err != nil {
+				return nil, &ParseError{Raw: formatted, Err: err}
+			}
+
+			for _, t := range legacy.Tables {
+				entry := L2MACEntry{GlobalMACCount: t.GlobalMACCount, LearnedMACCount: t.LearnedMACCount}
+
+				for _, m := range t.Entries {
+					entry.MACEntries = append(entry.MACEntries, MACEntry{
+						VlanName:         m.VlanName,
+						MACAddress:       m.MACAddress,
+						Age:              m.Age,
+						Flags:            m.Type,
+						LogicalInterface: strings.Join(m.Interfaces, ","),
+					})
+				}
+
+				ethtable.Entries = append(ethtable.Entries, entry)
+			}
+		} else {
+			if err := xml.Unmarshal([]byte(formatted), &ethtable); err != nil {
+				return nil, &ParseError{Raw: formatted, Err: err}
+			}
 		}
 
 		results.EthernetSwitch = ethtable
@@ -564,7 +1003,7 @@ func (j *Junos) View(view string, option ...string) (*Views, error) {
 			var srxinventory srxHardwareInventory
 
 			if err := xml.Unmarshal([]byte(formatted), &srxinventory); err != nil {
-				return nil, err
+				return nil, &ParseError{Raw: formatted, Err: err}
 			}
 
 			for _, c := range srxinventory.Chassis {
@@ -574,7 +1013,7 @@ func (j *Junos) View(view string, option ...string) (*Views, error) {
 			results.Inventory = inventory
 		} else {
 			if err := xml.Unmarshal([]byte(formatted), &inventory); err != nil {
-				return nil, err
+				return nil, &ParseError{Raw: formatted, Err: err}
 			}
 
 			results.Inventory = inventory
@@ -584,7 +1023,7 @@ func (j *Junos) View(view string, option ...string) (*Views, error) {
 		formatted := strings.Replace(reply.Data, "\n", "", -1)
 
 		if err := xml.Unmarshal([]byte(formatted), &vc); err != nil {
-			return nil, err
+			return nil, &ParseError{Raw: formatted, Err: err}
 		}
 
 		results.VirtualChassis = vc
@@ -593,7 +1032,7 @@ func (j *Junos) View(view string, option ...string) (*Views, error) {
 		formatted := strings.Replace(reply.Data, "\n", "", -1)
 
 		if err := xml.Unmarshal([]byte(formatted), &bgpTable); err != nil {
-			return nil, err
+			return nil, &ParseError{Raw: formatted, Err: err}
 		}
 
 		results.BGP = bgpTable
@@ -605,7 +1044,7 @@ func (j *Junos) View(view string, option ...string) (*Views, error) {
 			var srxstaticnats srxStaticNats
 
 			if err := xml.Unmarshal([]byte(formatted), &srxstaticnats); err != nil {
-				return nil, err
+				return nil, &ParseError{Raw: formatted, Err: err}
 			}
 
 			actualrules := len(srxstaticnats.Entries) / 2
@@ -623,7 +1062,7 @@ func (j *Junos) View(view string, option ...string) (*Views, error) {
 		} else {
 			var staticnatentry StaticNatEntry
 			if err := xml.Unmarshal([]byte(formatted), &staticnats); err != nil {
-				return nil, err
+				return nil, &ParseError{Raw: formatted, Err: err}
 			}
 
 			actualrules := len(staticnats.Entries)
@@ -641,7 +1080,7 @@ func (j *Junos) View(view string, option ...string) (*Views, error) {
 			var srxsourcenats srxSourceNats
 
 			if err := xml.Unmarshal([]byte(formatted), &srxsourcenats); err != nil {
-				return nil, err
+				return nil, &ParseError{Raw: formatted, Err: err}
 			}
 
 			actualrules := len(srxsourcenats.Entries) / 2
@@ -659,7 +1098,7 @@ func (j *Junos) View(view string, option ...string) (*Views, error) {
 		} else {
 			var sourcenatentry SourceNatEntry
 			if err := xml.Unmarshal([]byte(formatted), &sourcenats); err != nil {
-				return nil, err
+				return nil, &ParseError{Raw: formatted, Err: err}
 			}
 
 			actualrules := len(sourcenats.Entries)
@@ -677,7 +1116,7 @@ func (j *Junos) View(view string, option ...string) (*Views, error) {
 			var multistorage multiStorage
 
 			if err := xml.Unmarshal([]byte(formatted), &multistorage); err != nil {
-				return nil, err
+				return nil, &ParseError{Raw: formatted, Err: err}
 			}
 
 			for _, s := range multistorage.Entries {
@@ -688,7 +1127,7 @@ func (j *Junos) View(view string, option ...string) (*Views, error) {
 		} else {
 			var sysstorage SystemStorage
 			if err := xml.Unmarshal([]byte(formatted), &sysstorage); err != nil {
-				return nil, err
+				return nil, &ParseError{Raw: formatted, Err: err}
 			}
 
 			storage.Entries = append(storage.Entries, sysstorage)
@@ -703,7 +1142,7 @@ func (j *Junos) View(view string, option ...string) (*Views, error) {
 			var multifwpolicy srxFirewallPolicy
 
 			if err := xml.Unmarshal([]byte(formatted), &multifwpolicy); err != nil {
-				return nil, err
+				return nil, &ParseError{Raw: formatted, Err: err}
 			}
 
 			for _, s := range multifwpolicy.Entries {
@@ -713,12 +1152,736 @@ func (j *Junos) View(view string, option ...string) (*Views, error) {
 			results.FirewallPolicy = fwpolicy
 		} else {
 			if err := xml.Unmarshal([]byte(formatted), &fwpolicy); err != nil {
-				return nil, err
+				return nil, &ParseError{Raw: formatted, Err: err}
 			}
 
 			results.FirewallPolicy = fwpolicy
 		}
+	case "alarms":
+		var alarms SystemAlarms
+		formatted := strings.Replace(reply.Data, "\n", "", -1)
+
+		if strings.Contains(reply.Data, "multi-routing-engine-results") {
+			var srxalarms srxSystemAlarms
+
+			if err := xml.Unmarshal([]byte(formatted), &srxalarms); err != nil {
+				return nil, &ParseError{Raw: formatted, Err: err}
+			}
+
+			alarms.Entries = srxalarms.Entries
+			alarms.Count = len(srxalarms.Entries)
+		} else {
+			if err := xml.Unmarshal([]byte(formatted), &alarms); err != nil {
+				return nil, &ParseError{Raw: formatted, Err: err}
+			}
+		}
+
+		results.Alarms = alarms
+	case "environment":
+		var environment Environment
+		formatted := strings.Replace(reply.Data, "\n", "", -1)
+
+		if strings.Contains(reply.Data, "multi-routing-engine-results") {
+			var multienv multiEnvironment
+
+			if err := xml.Unmarshal([]byte(formatted), &multienv); err != nil {
+				return nil, &ParseError{Raw: formatted, Err: err}
+			}
+
+			environment.Items = multienv.Items
+		} else {
+			if err := xml.Unmarshal([]byte(formatted), &environment); err != nil {
+				return nil, &ParseError{Raw: formatted, Err: err}
+			}
+		}
+
+		results.Environment = environment
+	case "ospf":
+		var ospf OSPFNeighbors
+		formatted := strings.Replace(reply.Data, "\n", "", -1)
+
+		if err := xml.Unmarshal([]byte(formatted), &ospf); err != nil {
+			return nil, &ParseError{Raw: formatted, Err: err}
+		}
+
+		results.OSPF = ospf
+	case "routingengine":
+		var routeEngines RouteEngines
+		formatted := strings.Replace(reply.Data, "\n", "", -1)
+
+		if strings.Contains(reply.Data, "multi-routing-engine-results") {
+			var multiRE multiRouteEngines
+
+			if err := xml.Unmarshal([]byte(formatted), &multiRE); err != nil {
+				return nil, &ParseError{Raw: formatted, Err: err}
+			}
+
+			routeEngines.Entries = multiRE.Entries
+		} else {
+			if err := xml.Unmarshal([]byte(formatted), &routeEngines); err != nil {
+				return nil, &ParseError{Raw: formatted, Err: err}
+			}
+		}
+
+		results.RouteEngines = routeEngines
+	case "firewall":
+		var firewall FirewallFilters
+		formatted := strings.Replace(reply.Data, "\n", "", -1)
+
+		if err := xml.Unmarshal([]byte(formatted), &firewall); err != nil {
+			return nil, &ParseError{Raw: formatted, Err: err}
+		}
+
+		for i := range firewall.Entries {
+			for c := range firewall.Entries[i].Counters {
+				firewall.Entries[i].Counters[c].FilterName = firewall.Entries[i].Name
+			}
+		}
+
+		results.Firewall = firewall
+	case "fpc":
+		var fpcs FPCs
+		formatted := strings.Replace(reply.Data, "\n", "", -1)
+
+		if strings.Contains(reply.Data, "multi-routing-engine-results") {
+			var multifpcs multiFPCs
+
+			if err := xml.Unmarshal([]byte(formatted), &multifpcs); err != nil {
+				return nil, &ParseError{Raw: formatted, Err: err}
+			}
+
+			fpcs.Entries = multifpcs.Entries
+		} else {
+			if err := xml.Unmarshal([]byte(formatted), &fpcs); err != nil {
+				return nil, &ParseError{Raw: formatted, Err: err}
+			}
+		}
+
+		results.FPC = fpcs
+	case "poe":
+		var poe poeInformation
+		formatted := strings.Replace(reply.Data, "\n", "", -1)
+
+		if err := xml.Unmarshal([]byte(formatted), &poe); err != nil {
+			return nil, &ParseError{Raw: formatted, Err: err}
+		}
+
+		for _, i := range poe.Entries {
+			results.PoE.Entries = append(results.PoE.Entries, PoEInterface{
+				Interface:        strings.TrimSpace(i.Interface),
+				AdminStatus:      strings.TrimSpace(i.AdminStatus),
+				OperStatus:       strings.TrimSpace(i.OperStatus),
+				PowerLimit:       parseNumber(i.PowerLimit),
+				PowerConsumption: parseNumber(i.Power),
+				Priority:         strings.TrimSpace(i.Priority),
+				Class:            strings.TrimSpace(i.Class),
+			})
+		}
+	case "optics":
+		var optics opticsInformation
+		formatted := strings.Replace(reply.Data, "\n", "", -1)
+
+		if err := xml.Unmarshal([]byte(formatted), &optics); err != nil {
+			return nil, &ParseError{Raw: formatted, Err: err}
+		}
+
+		for _, i := range optics.Interfaces {
+			d := i.Diagnostics
+			module := OpticLane{
+				Interface:            strings.TrimSpace(i.Name),
+				RxPower:              parseNumber(d.RxPower),
+				TxPower:              parseNumber(d.TxPower),
+				Temperature:          parseNumber(d.Temperature),
+				RxPowerHighAlarm:     parseNumber(d.RxPowerHighAlarm),
+				RxPowerLowAlarm:      parseNumber(d.RxPowerLowAlarm),
+				TxPowerHighAlarm:     parseNumber(d.TxPowerHighAlarm),
+				TxPowerLowAlarm:      parseNumber(d.TxPowerLowAlarm),
+				TemperatureHighAlarm: parseNumber(d.TemperatureHighAlarm),
+				TemperatureLowAlarm:  parseNumber(d.TemperatureLowAlarm),
+			}
+
+			if len(d.Lanes) == 0 {
+				results.Optics.Entries = append(results.Optics.Entries, module)
+				continue
+			}
+
+			for _, l := range d.Lanes {
+				lane := module
+				lane.Lane = l.Index
+				lane.RxPower = parseNumber(l.RxPower)
+				lane.TxPower = parseNumber(l.TxPower)
+
+				results.Optics.Entries = append(results.Optics.Entries, lane)
+			}
+		}
+	case "routesummary":
+		var summary RouteSummary
+		formatted := strings.Replace(reply.Data, "\n", "", -1)
+
+		if err := xml.Unmarshal([]byte(formatted), &summary); err != nil {
+			return nil, &ParseError{Raw: formatted, Err: err}
+		}
+
+		results.RouteSummary = summary
+	case "dhcpbinding":
+		var bindings dhcpBindingInformation
+		formatted := strings.Replace(reply.Data, "\n", "", -1)
+
+		if err := xml.Unmarshal([]byte(formatted), &bindings); err != nil {
+			return nil, &ParseError{Raw: formatted, Err: err}
+		}
+
+		now := time.Now()
+		for _, b := range bindings.Entries {
+			binding := DHCPBinding{
+				IPAddress:        strings.TrimSpace(b.IPAddress),
+				MACAddress:       strings.TrimSpace(b.MACAddress),
+				State:            strings.TrimSpace(b.State),
+				LeaseExpiresText: strings.TrimSpace(b.LeaseExpires.Text),
+				Interface:        strings.TrimSpace(b.Interface),
+			}
+
+			// Older versions only report the type of binding, i.e. "dynamic."
+			if binding.State == "" {
+				binding.State = strings.TrimSpace(b.Type)
+			}
+
+			// Depending on the version, the seconds are either when the lease expires, or how long it has left.
+			switch secs := b.LeaseExpires.Seconds; {
+			case secs > 1000000000:
+				binding.LeaseExpires = time.Unix(secs, 0)
+			case secs > 0:
+				binding.LeaseExpires = now.Add(time.Duration(secs) * time.Second)
+			}
+
+			results.DHCPBindings.Entries = append(results.DHCPBindings.Entries, binding)
+		}
 	}
 
 	return &results, nil
 }
+
+// Interfaces returns the status of every physical interface on the device, along with its logical
+// interfaces and their addresses. This is the equivalent of "show interfaces terse."
+func (j *Junos) Interfaces() ([]Interface, error) {
+	var ints terseInterfaces
+	reply, err := j.exec(rpcInterfacesTerse)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := joinRPCErrors(reply); err != nil {
+		return nil, err
+	}
+
+	formatted := strings.Replace(reply.Data, "\n", "", -1)
+	if err := xml.Unmarshal([]byte(formatted), &ints); err != nil {
+		return nil, &ParseError{Raw: formatted, Err: fmt.Errorf("failed to parse interface information - %w", err)}
+	}
+
+	return ints.Entries, nil
+}
+
+// InterfaceStats contains the traffic and error counters of a physical interface, since they were last cleared.
+type InterfaceStats struct {
+	Name           string `xml:"name"`
+	InputBytes     int64  `xml:"traffic-statistics>input-bytes"`
+	OutputBytes    int64  `xml:"traffic-statistics>output-bytes"`
+	InputPackets   int64  `xml:"traffic-statistics>input-packets"`
+	OutputPackets  int64  `xml:"traffic-statistics>output-packets"`
+	InputErrors    int64  `xml:"input-error-list>input-errors"`
+	InputDrops     int64  `xml:"input-error-list>input-drops"`
+	OutputErrors   int64  `xml:"output-error-list>output-errors"`
+	OutputDrops    int64  `xml:"output-error-list>output-drops"`
+	LastFlapped    string `xml:"interface-flapped"`
+	StatsClearedAt string `xml:"statistics-cleared"`
+}
+
+type extensiveInterfaces struct {
+	Entries []InterfaceStats `xml:"physical-interface"`
+}
+
+// InterfaceStatistics returns the traffic and error counters of the given physical interface, such as "ge-0/0/0,"
+// from "show interfaces extensive." The counters are cumulative, so poll them and compare the values to get a rate.
+func (j *Junos) InterfaceStatistics(iface string) (*InterfaceStats, error) {
+	var ints extensiveInterfaces
+
+	if err := ValidateInterfaceName(iface); err != nil {
+		return nil, err
+	}
+
+	reply, err := j.exec(fmt.Sprintf(rpcInterfaceExtensive, escapeXML(iface)))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := joinRPCErrors(reply); err != nil {
+		return nil, err
+	}
+
+	formatted := strings.Replace(reply.Data, "\n", "", -1)
+	if err := xml.Unmarshal([]byte(formatted), &ints); err != nil {
+		return nil, &ParseError{Raw: formatted, Err: fmt.Errorf("failed to parse interface statistics - %w", err)}
+	}
+
+	if len(ints.Entries) == 0 {
+		return nil, fmt.Errorf("no statistics were returned for interface %s", iface)
+	}
+
+	return &ints.Entries[0], nil
+}
+
+// BGPNeighbors returns the summary of every BGP peer on the device, including its state, how long
+// it has been up or down (ElapsedTime), and its received and accepted prefix counts.
+func (j *Junos) BGPNeighbors() ([]BGPPeer, error) {
+	v, err := j.View("bgp")
+	if err != nil {
+		return nil, err
+	}
+
+	return v.BGP.Entries, nil
+}
+
+// ARPTable returns every entry in the ARP table on the device. If the table is empty, an empty
+// slice is returned.
+func (j *Junos) ARPTable() ([]ArpEntry, error) {
+	v, err := j.View("arp")
+	if err != nil {
+		return nil, err
+	}
+
+	return v.Arp.Entries, nil
+}
+
+// Routes returns the routes in the given routing table, such as "inet.0." If table is empty,
+// the routes from every routing table are returned.
+func (j *Junos) Routes(table string) ([]RouteTable, error) {
+	v, err := j.View("route", table)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.Route.RouteTables, nil
+}
+
+// Alarms returns every active system alarm on the device, the same as "show system alarms."
+func (j *Junos) Alarms() ([]Alarm, error) {
+	v, err := j.View("alarms")
+	if err != nil {
+		return nil, err
+	}
+
+	return v.Alarms.Entries, nil
+}
+
+// HardwareInventory returns every component in the chassis inventory (the same as "show chassis hardware") as
+// a flat list. Each chassis is followed by its modules, and each module by its sub-modules, so line cards, PICs
+// and SFPs are all included.
+func (j *Junos) HardwareInventory() ([]Component, error) {
+	var components []Component
+
+	v, err := j.View("inventory")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range v.Inventory.Chassis {
+		components = append(components, Component{Name: c.Name, SerialNumber: c.SerialNumber, Description: c.Description})
+
+		for _, m := range c.Modules {
+			components = append(components, Component{m.Name, m.Version, m.PartNumber, m.SerialNumber, m.Description})
+
+			for _, sm := range m.SubModules {
+				components = append(components, Component{sm.Name, sm.Version, sm.PartNumber, sm.SerialNumber, sm.Description})
+
+				for _, ssm := range sm.SubSubModules {
+					components = append(components, Component{ssm.Name, ssm.Version, ssm.PartNumber, ssm.SerialNumber, ssm.Description})
+
+					for _, sssm := range ssm.SubSubSubModules {
+						components = append(components, Component{sssm.Name, sssm.Version, sssm.PartNumber, sssm.SerialNumber, sssm.Description})
+					}
+				}
+			}
+		}
+	}
+
+	return components, nil
+}
+
+// LLDPNeighbors returns every LLDP neighbor seen by the device, the same as "show lldp neighbors."
+func (j *Junos) LLDPNeighbors() ([]LLDPNeighbor, error) {
+	v, err := j.View("lldp")
+	if err != nil {
+		return nil, err
+	}
+
+	return v.LLDPNeighbors.Entries, nil
+}
+
+// Environment returns the status of every environmental sensor on the device, the same as "show chassis environment."
+func (j *Junos) Environment() ([]EnvItem, error) {
+	v, err := j.View("environment")
+	if err != nil {
+		return nil, err
+	}
+
+	return v.Environment.Items, nil
+}
+
+// OSPFNeighbors returns every OSPF neighbor of the device, the same as "show ospf neighbor." If OSPF isn't
+// running on the device, an empty list and no error is returned.
+func (j *Junos) OSPFNeighbors() ([]OSPFNeighbor, error) {
+	v, err := j.View("ospf")
+	if err != nil {
+		if strings.Contains(err.Error(), "not running") {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return v.OSPF.Entries, nil
+}
+
+// RoutingEngineStatus returns the status of each routing engine on the device, the same as "show chassis routing-engine."
+func (j *Junos) RoutingEngineStatus() ([]RouteEngine, error) {
+	v, err := j.View("routingengine")
+	if err != nil {
+		return nil, err
+	}
+
+	return v.RouteEngines.Entries, nil
+}
+
+// VLANs returns every VLAN configured on the switch, along with its member interfaces, the same as "show vlans."
+// Both ELS and legacy (non-ELS) EX switches are supported.
+func (j *Junos) VLANs() ([]Vlan, error) {
+	v, err := j.View("vlan")
+	if err != nil {
+		return nil, err
+	}
+
+	return v.Vlan.Entries, nil
+}
+
+// FirewallCounters returns the counters of the given firewall filter, or of every firewall filter on the device if
+// filter is empty, the same as "show firewall."
+func (j *Junos) FirewallCounters(filter string) ([]FirewallCounter, error) {
+	var counters []FirewallCounter
+
+	v, err := j.View("firewall", filter)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range v.Firewall.Entries {
+		counters = append(counters, f.Counters...)
+	}
+
+	return counters, nil
+}
+
+// FPCStatus returns the status and resource utilization of each FPC on the device, the same as "show chassis fpc."
+func (j *Junos) FPCStatus() ([]FPC, error) {
+	v, err := j.View("fpc")
+	if err != nil {
+		return nil, err
+	}
+
+	return v.FPC.Entries, nil
+}
+
+// EthernetSwitching returns every MAC address in the ethernet-switching table of the switch, the same as "show
+// ethernet-switching table." Both ELS and legacy (non-ELS) EX switches are supported.
+func (j *Junos) EthernetSwitching() ([]MACEntry, error) {
+	var entries []MACEntry
+
+	v, err := j.View("ethernetswitch")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range v.EthernetSwitch.Entries {
+		entries = append(entries, e.MACEntries...)
+	}
+
+	return entries, nil
+}
+
+// Storage returns every file system on the device, the same as "show system storage." On devices with multiple
+// routing engines, or clustered SRX's, the file systems of all of them are returned.
+func (j *Junos) Storage() ([]FileSystem, error) {
+	var filesystems []FileSystem
+
+	v, err := j.View("storage")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range v.Storage.Entries {
+		filesystems = append(filesystems, s.FileSystems...)
+	}
+
+	return filesystems, nil
+}
+
+// PoEStatus returns the PoE (Power over Ethernet) status of every interface on the switch, the same as
+// "show poe interface."
+func (j *Junos) PoEStatus() ([]PoEInterface, error) {
+	v, err := j.View("poe")
+	if err != nil {
+		return nil, err
+	}
+
+	return v.PoE.Entries, nil
+}
+
+// OpticsDiagnostics returns the optics (DOM) diagnostics for each lane of the optic in the given interface, or of
+// every optical interface on the device if iface is empty, the same as "show interfaces diagnostics optics."
+func (j *Junos) OpticsDiagnostics(iface string) ([]OpticLane, error) {
+	v, err := j.View("optics", iface)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.Optics.Entries, nil
+}
+
+// AllOptics returns the optics (DOM) diagnostics of every optical interface on the device in a single RPC, keyed by
+// the name of the interface. Each interface has an entry for each lane of its optic, the same as OpticsDiagnostics.
+func (j *Junos) AllOptics() (map[string][]OpticLane, error) {
+	v, err := j.View("optics")
+	if err != nil {
+		return nil, err
+	}
+
+	optics := make(map[string][]OpticLane)
+	for _, l := range v.Optics.Entries {
+		optics[l.Interface] = append(optics[l.Interface], l)
+	}
+
+	return optics, nil
+}
+
+// RouteSummary returns a summary of each routing table on the device, the same as "show route summary."
+func (j *Junos) RouteSummary() ([]RouteTableSummary, error) {
+	v, err := j.View("routesummary")
+	if err != nil {
+		return nil, err
+	}
+
+	return v.RouteSummary.Tables, nil
+}
+
+// DHCPBindings returns every lease handed out by the DHCP server on the device, the same as "show dhcp server binding."
+func (j *Junos) DHCPBindings() ([]DHCPBinding, error) {
+	v, err := j.View("dhcpbinding")
+	if err != nil {
+		return nil, err
+	}
+
+	return v.DHCPBindings.Entries, nil
+}
+
+// Process contains information about a process running on the routing engine. Resident is the amount of memory
+// the process is using, as displayed by the device, i.e. "45M." MemoryPercent is an approximation, based on the
+// total amount of memory reported by the device. Kernel is true for the kernel's own threads, such as "idle" and
+// "intr," rather than daemons.
+type Process struct {
+	PID           int
+	User          string
+	Name          string
+	CPUPercent    float64
+	Resident      string
+	MemoryPercent float64
+	Kernel        bool
+}
+
+// TopProcesses returns the top n processes running on the routing engine, sorted by their CPU usage, the same as
+// "show system processes extensive." If n is 0, the top 10 processes are returned. The kernel's threads are left out,
+// since the idle threads are always at the top on a quiet routing engine; to include them (i.e. to spot an interrupt
+// storm), set includeKernel to true (optional).
+func (j *Junos) TopProcesses(n int, includeKernel ...bool) ([]Process, error) {
+	if n < 0 {
+		return nil, errors.New("the number of processes can't be negative")
+	}
+
+	if n == 0 {
+		n = 10
+	}
+
+	output, err := j.Command("show system processes extensive", "text")
+	if err != nil {
+		return nil, err
+	}
+
+	var processes []Process
+	for _, p := range parseProcesses(output) {
+		if !p.Kernel || (len(includeKernel) > 0 && includeKernel[0]) {
+			processes = append(processes, p)
+		}
+	}
+
+	sort.SliceStable(processes, func(a, b int) bool {
+		return processes[a].CPUPercent > processes[b].CPUPercent
+	})
+
+	if len(processes) > n {
+		processes = processes[:n]
+	}
+
+	return processes, nil
+}
+
+// parseProcesses parses the output of top, which is what "show system processes extensive" displays.
+func parseProcesses(output string) []Process {
+	var processes []Process
+	var header []string
+	var totalMemory float64
+	pidCol, userCol, sizeCol, resCol, cpuCol := -1, -1, -1, -1, -1
+
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if header == nil {
+			switch fields[0] {
+			case "Mem:":
+				// i.e. "Mem: 1024M Active, 300M Inact, 200M Wired, 80M Buf, 400M Free" - Buf is part of Wired.
+				for _, part := range strings.Split(strings.TrimPrefix(strings.TrimSpace(line), "Mem:"), ",") {
+					if p := strings.Fields(part); len(p) == 2 && p[1] != "Buf" {
+						totalMemory += parseSize(p[0])
+					}
+				}
+			case "PID":
+				header = fields
+				for i, f := range header {
+					switch f {
+					case "PID":
+						pidCol = i
+					case "USERNAME":
+						userCol = i
+					case "SIZE":
+						sizeCol = i
+					case "RES":
+						resCol = i
+					case "WCPU", "CPU", "%CPU":
+						cpuCol = i
+					}
+				}
+
+				if pidCol < 0 || cpuCol < 0 {
+					return nil
+				}
+			}
+
+			continue
+		}
+
+		if len(fields) < len(header) {
+			continue
+		}
+
+		pid, err := strconv.Atoi(fields[pidCol])
+		if err != nil {
+			continue
+		}
+
+		p := Process{
+			PID:        pid,
+			Name:       strings.Join(fields[cpuCol+1:], " "),
+			CPUPercent: parseNumber(fields[cpuCol]),
+		}
+
+		if userCol >= 0 {
+			p.User = fields[userCol]
+		}
+
+		// Kernel threads don't have any memory of their own, and their names are shown as "idle{idle: cpu0}"
+		// or "[idle]," depending on the release.
+		p.Kernel = pid == 0 || strings.HasPrefix(p.Name, "idle") || strings.HasPrefix(p.Name, "[") ||
+			strings.HasPrefix(p.Name, "{") || (sizeCol >= 0 && parseSize(fields[sizeCol]) == 0)
+
+		if resCol >= 0 {
+			p.Resident = fields[resCol]
+
+			if totalMemory > 0 {
+				p.MemoryPercent = parseSize(p.Resident) / totalMemory * 100
+			}
+		}
+
+		processes = append(processes, p)
+	}
+
+	return processes
+}
+
+// parseSize returns the number of bytes in a size displayed by top, i.e. "45M" or "1024K."
+func parseSize(s string) float64 {
+	n := parseNumber(s)
+
+	switch strings.ToUpper(strings.TrimLeft(s, "+-.0123456789")) {
+	case "K":
+		n *= 1 << 10
+	case "M":
+		n *= 1 << 20
+	case "G":
+		n *= 1 << 30
+	case "T":
+		n *= 1 << 40
+	}
+
+	return n
+}
+
+// SecurityPolicy contains a single security policy, along with the zones it applies to.
+type SecurityPolicy struct {
+	FromZone             string
+	ToZone               string
+	Name                 string
+	SourceAddresses      []string
+	DestinationAddresses []string
+	Applications         []string
+	Action               string
+}
+
+// SecurityPolicies returns every security policy on the device, in the order they're evaluated, the same as
+// "show security policies." This is only available on SRX's - an error is returned on any other platform. On
+// a chassis cluster, the policies are only returned once, since they're the same on each node.
+func (j *Junos) SecurityPolicies() ([]SecurityPolicy, error) {
+	var policies []SecurityPolicy
+
+	if len(j.Platform) > 0 && !strings.Contains(j.Platform[0].Model, "SRX") {
+		return nil, errors.New("security policy information is only available on SRX's")
+	}
+
+	v, err := j.View("firewallpolicy")
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	for _, c := range v.FirewallPolicy.Entries {
+		for _, r := range c.Rules {
+			key := c.SourceZone + "\x00" + c.DestinationZone + "\x00" + r.Name
+			if seen[key] {
+				continue
+			}
+
+			seen[key] = true
+			policies = append(policies, SecurityPolicy{
+				FromZone:             c.SourceZone,
+				ToZone:               c.DestinationZone,
+				Name:                 r.Name,
+				SourceAddresses:      r.SourceAddresses,
+				DestinationAddresses: r.DestinationAddresses,
+				Applications:         r.Applications,
+				Action:               r.PolicyAction,
+			})
+		}
+	}
+
+	return policies, nil
+}
@@ -0,0 +1,45 @@
+package junos
+
+import "testing"
+
+func TestParseProcesses(t *testing.T) {
+	output := `last pid: 12345;  load averages:  0.10,  0.12,  0.09  up 10+01:02:03    12:00:00
+95 processes:  2 running, 92 sleeping, 1 zombie
+
+Mem: 500M Active, 200M Inact, 250M Wired, 100M Buf, 1048M Free
+Swap: 2048M Total, 2048M Free
+
+  PID USERNAME       THR PRI NICE   SIZE    RES STATE    C   TIME    WCPU COMMAND
+   11 root             1 155 ki31     0K    16K RUN      0 100.0H  97.56% idle{idle: cpu0}
+ 1503 root             2  20    0   727M   100M kqread   1   1:23   2.50% rpd{rpd}
+   12 root            19 -52    -     0K   304K WAIT     0  10:10   1.00% intr{swi4: clock}
+`
+
+	want := []struct {
+		pid    int
+		name   string
+		cpu    float64
+		kernel bool
+	}{
+		{11, "idle{idle: cpu0}", 97.56, true},
+		{1503, "rpd{rpd}", 2.5, false},
+		{12, "intr{swi4: clock}", 1, true},
+	}
+
+	processes := parseProcesses(output)
+	if len(processes) != len(want) {
+		t.Fatalf("parseProcesses() returned %d processes, want %d", len(processes), len(want))
+	}
+
+	for i, w := range want {
+		p := processes[i]
+		if p.PID != w.pid || p.Name != w.name || p.CPUPercent != w.cpu || p.Kernel != w.kernel {
+			t.Errorf("process %d = %+v, want PID %d, Name %q, CPUPercent %v, Kernel %v", i, p, w.pid, w.name, w.cpu, w.kernel)
+		}
+	}
+
+	// 100M of the 1998M (Active + Inact + Wired + Free) on the device.
+	if got := processes[1].MemoryPercent; got < 5 || got > 5.01 {
+		t.Errorf("MemoryPercent = %v, want about 5.005", got)
+	}
+}